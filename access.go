@@ -0,0 +1,204 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const defaultAccessConcurrency = 8
+
+// Access is the access a user has to a repository.
+type Access string
+
+// Access levels, from the highest to the lowest. AccessPublic is the read
+// access that every user has to public repositories.
+const (
+	AccessWrite    Access = "write"
+	AccessReadOnly Access = "read-only"
+	AccessPublic   Access = "public"
+	AccessNone     Access = ""
+)
+
+// RepositoryAccess is the access of each user to a repository.
+type RepositoryAccess struct {
+	Name    string            `json:"name"`
+	Public  bool              `json:"public"`
+	NoUsers bool              `json:"noUsers"`
+	Users   map[string]Access `json:"users"`
+}
+
+// AccessReport is a user by repository matrix of access levels.
+type AccessReport struct {
+	// Users lists every user with write or read-only access to at least
+	// one repository, sorted by name.
+	Users []string `json:"users"`
+
+	// Repositories is sorted by name.
+	Repositories []RepositoryAccess `json:"repositories"`
+
+	// Errors holds the repositories that could not be fetched.
+	Errors map[string]error `json:"-"`
+}
+
+// BuildAccessReport fetches the given repositories, at most concurrency at
+// a time (8 when zero), and builds their access report. Repositories that
+// fail are left out of the report and listed in its Errors, and the error
+// returned describes all of them.
+func BuildAccessReport(ctx context.Context, api API, names []string, concurrency int) (*AccessReport, error) {
+	if concurrency <= 0 {
+		concurrency = defaultAccessConcurrency
+	}
+	report := AccessReport{Errors: map[string]error{}}
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		sem   = make(chan struct{}, concurrency)
+		repos []Repository
+	)
+	for _, name := range names {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(name string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			repo, err := api.GetRepository(ctx, name)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				report.Errors[name] = err
+				return
+			}
+			if repo.Name == "" {
+				repo.Name = name
+			}
+			repos = append(repos, repo)
+		}(name)
+	}
+	wg.Wait()
+	report.add(repos)
+	if len(report.Errors) > 0 {
+		failed := make([]string, 0, len(report.Errors))
+		for name, err := range report.Errors {
+			failed = append(failed, fmt.Sprintf("%s: %s", name, err))
+		}
+		sort.Strings(failed)
+		return &report, fmt.Errorf("failed to get %d repositories: %s", len(failed), strings.Join(failed, "; "))
+	}
+	return &report, nil
+}
+
+func (r *AccessReport) add(repos []Repository) {
+	users := map[string]bool{}
+	for _, repo := range repos {
+		access := RepositoryAccess{
+			Name:    repo.Name,
+			Public:  repo.IsPublic,
+			NoUsers: len(repo.Users) == 0 && len(repo.ReadOnlyUsers) == 0,
+			Users:   map[string]Access{},
+		}
+		for _, u := range repo.ReadOnlyUsers {
+			access.Users[u] = AccessReadOnly
+			users[u] = true
+		}
+		for _, u := range repo.Users {
+			access.Users[u] = AccessWrite
+			users[u] = true
+		}
+		r.Repositories = append(r.Repositories, access)
+	}
+	sort.Slice(r.Repositories, func(i, j int) bool {
+		return r.Repositories[i].Name < r.Repositories[j].Name
+	})
+	for u := range users {
+		r.Users = append(r.Users, u)
+	}
+	sort.Strings(r.Users)
+}
+
+// Access returns the access of the user to the repository, or AccessNone
+// if the repository is not in the report.
+func (r *AccessReport) Access(user, repo string) Access {
+	for _, access := range r.Repositories {
+		if access.Name == repo {
+			return access.access(user)
+		}
+	}
+	return AccessNone
+}
+
+func (r *RepositoryAccess) access(user string) Access {
+	if access, ok := r.Users[user]; ok {
+		return access
+	}
+	if r.Public {
+		return AccessPublic
+	}
+	return AccessNone
+}
+
+func (r *AccessReport) rows() [][]string {
+	header := append([]string{"repository", "public", "no users"}, r.Users...)
+	rows := [][]string{header}
+	for _, repo := range r.Repositories {
+		row := []string{repo.Name, flag(repo.Public), flag(repo.NoUsers)}
+		for _, u := range r.Users {
+			row = append(row, string(repo.access(u)))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func flag(b bool) string {
+	if b {
+		return "yes"
+	}
+	return ""
+}
+
+// WriteCSV writes the report as CSV, with a row per repository and a
+// column per user.
+func (r *AccessReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(r.rows()); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *AccessReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteMarkdown writes the report as a Markdown table, with a row per
+// repository and a column per user.
+func (r *AccessReport) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	for i, row := range r.rows() {
+		b.WriteString("|")
+		for _, cell := range row {
+			b.WriteString(" " + strings.ReplaceAll(cell, "|", `\|`) + " |")
+		}
+		b.WriteString("\n")
+		if i == 0 {
+			b.WriteString("|" + strings.Repeat(" --- |", len(row)) + "\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
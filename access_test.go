@@ -0,0 +1,79 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"gopkg.in/check.v1"
+)
+
+func accessServer() *httptest.Server {
+	repos := map[string]Repository{
+		"api":    {Name: "api", Users: []string{"alice", "bob"}, ReadOnlyUsers: []string{"carol"}},
+		"site":   {Name: "site", Users: []string{"carol"}, IsPublic: true},
+		"legacy": {Name: "legacy"},
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		repo, ok := repos[strings.TrimPrefix(r.URL.Path, "/repository/")]
+		if !ok {
+			http.Error(w, "repository not found", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(repo)
+	}))
+}
+
+func (s *S) TestBuildAccessReport(c *check.C) {
+	ts := accessServer()
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	report, err := BuildAccessReport(ctx, &client, []string{"site", "api", "legacy"}, 2)
+	c.Assert(err, check.IsNil)
+	c.Assert(report.Users, check.DeepEquals, []string{"alice", "bob", "carol"})
+	c.Assert(report.Access("alice", "api"), check.Equals, AccessWrite)
+	c.Assert(report.Access("carol", "api"), check.Equals, AccessReadOnly)
+	c.Assert(report.Access("alice", "site"), check.Equals, AccessPublic)
+	c.Assert(report.Access("alice", "legacy"), check.Equals, AccessNone)
+	c.Assert(report.Access("alice", "unknown"), check.Equals, AccessNone)
+	var csv bytes.Buffer
+	err = report.WriteCSV(&csv)
+	c.Assert(err, check.IsNil)
+	c.Assert(csv.String(), check.Equals, `repository,public,no users,alice,bob,carol
+api,,,write,write,read-only
+legacy,,yes,,,
+site,yes,,public,public,write
+`)
+	var md bytes.Buffer
+	err = report.WriteMarkdown(&md)
+	c.Assert(err, check.IsNil)
+	c.Assert(md.String(), check.Equals, `| repository | public | no users | alice | bob | carol |
+| --- | --- | --- | --- | --- | --- |
+| api |  |  | write | write | read-only |
+| legacy |  | yes |  |  |  |
+| site | yes |  | public | public | write |
+`)
+	var out bytes.Buffer
+	err = report.WriteJSON(&out)
+	c.Assert(err, check.IsNil)
+	var decoded AccessReport
+	err = json.Unmarshal(out.Bytes(), &decoded)
+	c.Assert(err, check.IsNil)
+	c.Assert(decoded.Repositories, check.DeepEquals, report.Repositories)
+}
+
+func (s *S) TestBuildAccessReportErrors(c *check.C) {
+	ts := accessServer()
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	report, err := BuildAccessReport(ctx, &client, []string{"api", "missing"}, 0)
+	c.Assert(err, check.ErrorMatches, "(?s)failed to get 1 repositories: missing: repository not found.*")
+	c.Assert(report.Errors, check.HasLen, 1)
+	c.Assert(report.Repositories, check.HasLen, 1)
+}
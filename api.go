@@ -0,0 +1,44 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"context"
+	"io"
+)
+
+// Repository is the metadata of a repository, as returned by
+// NewRepository and GetRepository.
+type Repository = repository
+
+// User is a Gandalf user, as returned by NewUser.
+type User = user
+
+// API is the set of operations provided by the Gandalf client. Code that
+// depends on API instead of *Client can be tested with the fake from the
+// gandalftest package.
+type API interface {
+	NewRepository(ctx context.Context, name string, users []string, isPublic bool, opts ...CallOption) (Repository, error)
+	GetRepository(ctx context.Context, name string, opts ...CallOption) (Repository, error)
+	RemoveRepository(ctx context.Context, name string, opts ...CallOption) error
+	NewUser(ctx context.Context, name string, keys map[string]string, opts ...CallOption) (User, error)
+	RemoveUser(ctx context.Context, name string, opts ...CallOption) error
+	GrantAccess(ctx context.Context, rNames, uNames []string, opts ...CallOption) error
+	RevokeAccess(ctx context.Context, rNames, uNames []string, opts ...CallOption) error
+	AddKey(ctx context.Context, uName string, key map[string]string, opts ...CallOption) error
+	UpdateKey(ctx context.Context, uName, kName, kBody string, opts ...CallOption) error
+	RemoveKey(ctx context.Context, uName, kName string, opts ...CallOption) error
+	ListKeys(ctx context.Context, uName string, opts ...CallOption) (map[string]string, error)
+	GetDiff(ctx context.Context, repo, previousCommit, lastCommit string, opts ...CallOption) (string, error)
+	GetLog(ctx context.Context, repo, ref, path string, total int, opts ...CallOption) (Log, error)
+	GetCommit(ctx context.Context, repo, ref string, opts ...CallOption) (CommitDetail, error)
+	Compare(ctx context.Context, repo, base, head string, opts ...CallOption) (Comparison, error)
+	GetBranches(ctx context.Context, repo string, opts ...CallOption) ([]Branch, error)
+	GetTree(ctx context.Context, repo, ref, path string, opts ...CallOption) ([]TreeEntry, error)
+	GetArchive(ctx context.Context, repo, ref string, format ArchiveFormat, opts ...CallOption) (io.ReadCloser, error)
+	GetHealthCheck(ctx context.Context, opts ...CallOption) ([]byte, error)
+}
+
+var _ API = &Client{}
@@ -0,0 +1,113 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Outcomes of audited operations.
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+	AuditPlanned = "planned"
+)
+
+// AuditEntry records a mutating call made by the client. Key material is
+// never included in the parameters: keys are replaced by their SHA256
+// fingerprints.
+type AuditEntry struct {
+	Time      time.Time              `json:"time"`
+	Operation string                 `json:"operation"`
+	Params    map[string]interface{} `json:"params"`
+	Outcome   string                 `json:"outcome"`
+	Error     string                 `json:"error,omitempty"`
+	LatencyMS float64                `json:"latency_ms"`
+}
+
+// AuditSink receives the audit entries of a Client.
+type AuditSink interface {
+	WriteAudit(entry AuditEntry) error
+}
+
+// AuditWriter is an AuditSink that writes entries to an io.Writer in the
+// JSON Lines format. It is safe for concurrent use.
+type AuditWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewAuditWriter returns an AuditWriter that writes to w.
+func NewAuditWriter(w io.Writer) *AuditWriter {
+	return &AuditWriter{w: w}
+}
+
+// OpenAuditFile returns an AuditWriter that appends to the file in the
+// given path, creating it if needed.
+func OpenAuditFile(path string) (*AuditWriter, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return NewAuditWriter(f), nil
+}
+
+// WriteAudit writes the entry as a single line.
+func (w *AuditWriter) WriteAudit(entry AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err = w.w.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the underlying writer, if it is an io.Closer.
+func (w *AuditWriter) Close() error {
+	if closer, ok := w.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// audit sends an entry for the given operation to the audit sink. Errors
+// writing the entry are reported to OnAuditError, and do not affect the
+// result of the operation, which has already been applied.
+func (c *Client) audit(start time.Time, operation string, params map[string]interface{}, err error) {
+	if c.Audit == nil {
+		return
+	}
+	entry := AuditEntry{
+		Time:      start.UTC(),
+		Operation: operation,
+		Params:    params,
+		Outcome:   AuditSuccess,
+		LatencyMS: float64(time.Since(start)) / float64(time.Millisecond),
+	}
+	if err != nil {
+		entry.Outcome = AuditFailure
+		entry.Error = err.Error()
+	} else if c.DryRun {
+		entry.Outcome = AuditPlanned
+	}
+	if err := c.Audit.WriteAudit(entry); err != nil && c.OnAuditError != nil {
+		c.OnAuditError(entry, err)
+	}
+}
+
+// fingerprints maps the keys to their fingerprints.
+func fingerprints(keys map[string]string) map[string]string {
+	fps := make(map[string]string, len(keys))
+	for name, key := range keys {
+		fps[name] = keyFingerprint(key)
+	}
+	return fps
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/check.v1"
+)
+
+type memoryAuditSink struct {
+	entries []AuditEntry
+}
+
+func (s *memoryAuditSink) WriteAudit(entry AuditEntry) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *S) TestAuditMutatingCalls(c *check.C) {
+	h := testHandler{}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	var sink memoryAuditSink
+	client := Client{Endpoint: ts.URL, Audit: &sink}
+	_, err := client.NewRepository(ctx, "proj1", []string{"user1"}, false)
+	c.Assert(err, check.IsNil)
+	_, err = client.NewUser(ctx, "user1", map[string]string{"mykey": testKey})
+	c.Assert(err, check.IsNil)
+	c.Assert(client.GrantAccess(ctx, []string{"proj1"}, []string{"user1"}), check.IsNil)
+	c.Assert(client.RevokeAccess(ctx, []string{"proj1"}, []string{"user1"}), check.IsNil)
+	c.Assert(client.AddKey(ctx, "user1", map[string]string{"other": testKey}), check.IsNil)
+	c.Assert(client.UpdateKey(ctx, "user1", "other", testKey), check.IsNil)
+	c.Assert(client.RemoveKey(ctx, "user1", "other"), check.IsNil)
+	c.Assert(client.RemoveUser(ctx, "user1"), check.IsNil)
+	c.Assert(client.RemoveRepository(ctx, "proj1"), check.IsNil)
+	_, err = client.GetRepository(ctx, "proj1")
+	c.Assert(err, check.NotNil)
+	var operations []string
+	for _, entry := range sink.entries {
+		operations = append(operations, entry.Operation)
+		c.Assert(entry.Outcome, check.Equals, AuditSuccess)
+		c.Assert(entry.Time.IsZero(), check.Equals, false)
+		c.Assert(entry.LatencyMS >= 0, check.Equals, true)
+	}
+	c.Assert(operations, check.DeepEquals, []string{
+		"NewRepository", "NewUser", "GrantAccess", "RevokeAccess", "AddKey",
+		"UpdateKey", "RemoveKey", "RemoveUser", "RemoveRepository",
+	})
+	c.Assert(sink.entries[0].Params, check.DeepEquals, map[string]interface{}{
+		"name": "proj1", "users": []string{"user1"}, "ispublic": false,
+	})
+	c.Assert(sink.entries[1].Params, check.DeepEquals, map[string]interface{}{
+		"name": "user1", "keys": map[string]string{"mykey": testKeyFingerprint},
+	})
+	c.Assert(sink.entries[5].Params, check.DeepEquals, map[string]interface{}{
+		"user": "user1", "key": "other", "fingerprint": testKeyFingerprint,
+	})
+}
+
+func (s *S) TestAuditFailureAndDryRun(c *check.C) {
+	h := errorHandler{}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	var sink memoryAuditSink
+	client := Client{Endpoint: ts.URL, Audit: &sink}
+	err := client.RemoveUser(ctx, "user1")
+	c.Assert(err, check.NotNil)
+	client.DryRun = true
+	err = client.RemoveUser(ctx, "user1")
+	c.Assert(err, check.IsNil)
+	c.Assert(sink.entries, check.HasLen, 2)
+	c.Assert(sink.entries[0].Outcome, check.Equals, AuditFailure)
+	c.Assert(sink.entries[0].Error, check.Equals, "Error performing requested operation\n")
+	c.Assert(sink.entries[1].Outcome, check.Equals, AuditPlanned)
+}
+
+type failingAuditSink struct{}
+
+func (failingAuditSink) WriteAudit(entry AuditEntry) error {
+	return errors.New("no space left on device")
+}
+
+func (s *S) TestAuditErrors(c *check.C) {
+	h := testHandler{}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	var (
+		entries []AuditEntry
+		errs    []error
+	)
+	client := Client{
+		Endpoint: ts.URL,
+		Audit:    failingAuditSink{},
+		OnAuditError: func(entry AuditEntry, err error) {
+			entries = append(entries, entry)
+			errs = append(errs, err)
+		},
+	}
+	err := client.RemoveUser(ctx, "user1")
+	c.Assert(err, check.IsNil)
+	c.Assert(entries, check.HasLen, 1)
+	c.Assert(entries[0].Operation, check.Equals, "RemoveUser")
+	c.Assert(entries[0].Outcome, check.Equals, AuditSuccess)
+	c.Assert(errs[0], check.ErrorMatches, "no space left on device")
+	client.OnAuditError = nil
+	err = client.RemoveUser(ctx, "user1")
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestAuditWriter(c *check.C) {
+	h := testHandler{}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	var buf bytes.Buffer
+	client := Client{Endpoint: ts.URL, Audit: NewAuditWriter(&buf)}
+	c.Assert(client.AddKey(ctx, "user1", map[string]string{"mykey": testKey}), check.IsNil)
+	c.Assert(client.RemoveKey(ctx, "user1", "mykey"), check.IsNil)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	c.Assert(lines, check.HasLen, 2)
+	c.Assert(strings.Contains(buf.String(), "AAAAC3NzaC1lZDI1NTE5"), check.Equals, false)
+	var entry map[string]interface{}
+	err := json.Unmarshal([]byte(lines[0]), &entry)
+	c.Assert(err, check.IsNil)
+	c.Assert(entry["operation"], check.Equals, "AddKey")
+	c.Assert(entry["outcome"], check.Equals, "success")
+	c.Assert(entry["params"], check.DeepEquals, map[string]interface{}{
+		"user": "user1", "keys": map[string]interface{}{"mykey": testKeyFingerprint},
+	})
+}
+
+func (s *S) TestOpenAuditFile(c *check.C) {
+	path := filepath.Join(c.MkDir(), "audit.jsonl")
+	for i := 0; i < 2; i++ {
+		w, err := OpenAuditFile(path)
+		c.Assert(err, check.IsNil)
+		err = w.WriteAudit(AuditEntry{Operation: "RemoveUser", Outcome: AuditSuccess})
+		c.Assert(err, check.IsNil)
+		c.Assert(w.Close(), check.IsNil)
+	}
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, check.IsNil)
+	c.Assert(strings.Count(string(data), "\n"), check.Equals, 2)
+}
@@ -0,0 +1,257 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tsuru/go-gandalfclient/internal/atomicfile"
+)
+
+// BackupManifestFile is the name of the manifest written to the backup
+// directory.
+const BackupManifestFile = "manifest.json"
+
+const defaultBackupConcurrency = 4
+
+// BackupOptions configures Backup.
+type BackupOptions struct {
+	// Dir is the directory where archives and the manifest are written.
+	Dir string
+
+	// AllBranches makes Backup archive every branch, instead of only the
+	// default one (HEAD).
+	AllBranches bool
+
+	// Format of the archives, defaulting to TarGz.
+	Format ArchiveFormat
+
+	// Concurrency is the maximum number of repositories backed up at the
+	// same time, defaulting to 4.
+	Concurrency int
+}
+
+// BackupManifest describes the contents of a backup directory.
+type BackupManifest struct {
+	UpdatedAt    time.Time                    `json:"updatedAt"`
+	Repositories map[string]*RepositoryBackup `json:"repositories"`
+}
+
+// RepositoryBackup holds the metadata and archives of a repository.
+type RepositoryBackup struct {
+	Repository Repository  `json:"repository"`
+	Refs       []RefBackup `json:"refs"`
+}
+
+// RefBackup is the archive of a ref of a repository.
+type RefBackup struct {
+	Ref    string    `json:"ref"`
+	Head   Commit    `json:"head"`
+	File   string    `json:"file"`
+	SHA256 string    `json:"sha256"`
+	Size   int64     `json:"size"`
+	Time   time.Time `json:"time"`
+}
+
+// BackupReport is the result of a Backup run. Downloaded and Unchanged
+// hold "repository@ref" entries.
+type BackupReport struct {
+	Manifest   *BackupManifest
+	Downloaded []string
+	Unchanged  []string
+	Errors     map[string]error
+}
+
+// Backup downloads the archives of the given repositories into opts.Dir,
+// along with a manifest holding their metadata, head commits and SHA-256
+// checksums.
+//
+// When the directory already holds a backup, archives whose head commit
+// did not change are not downloaded again. Repositories that fail keep
+// their previous entries in the manifest, and their errors are reported
+// in the returned report as well as in the error.
+func Backup(ctx context.Context, api API, names []string, opts BackupOptions) (*BackupReport, error) {
+	if opts.Format == "" {
+		opts.Format = TarGz
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultBackupConcurrency
+	}
+	if err := os.MkdirAll(opts.Dir, 0755); err != nil {
+		return nil, err
+	}
+	previous, err := readBackupManifest(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	report := BackupReport{
+		Manifest: &BackupManifest{Repositories: map[string]*RepositoryBackup{}},
+		Errors:   map[string]error{},
+	}
+	for name, backup := range previous.Repositories {
+		report.Manifest.Repositories[name] = backup
+	}
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, opts.Concurrency)
+	)
+	for _, name := range names {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(name string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			backup, downloaded, unchanged, err := backupRepository(ctx, api, name, previous.Repositories[name], opts)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				report.Errors[name] = err
+				return
+			}
+			report.Manifest.Repositories[name] = backup
+			report.Downloaded = append(report.Downloaded, downloaded...)
+			report.Unchanged = append(report.Unchanged, unchanged...)
+		}(name)
+	}
+	wg.Wait()
+	sort.Strings(report.Downloaded)
+	sort.Strings(report.Unchanged)
+	report.Manifest.UpdatedAt = time.Now().UTC()
+	if err := writeBackupManifest(opts.Dir, report.Manifest); err != nil {
+		return &report, err
+	}
+	if len(report.Errors) > 0 {
+		failed := make([]string, 0, len(report.Errors))
+		for name, err := range report.Errors {
+			failed = append(failed, fmt.Sprintf("%s: %s", name, err))
+		}
+		sort.Strings(failed)
+		return &report, fmt.Errorf("backup failed for %d repositories: %s", len(failed), strings.Join(failed, "; "))
+	}
+	return &report, nil
+}
+
+func backupRepository(ctx context.Context, api API, name string, previous *RepositoryBackup, opts BackupOptions) (*RepositoryBackup, []string, []string, error) {
+	repo, err := api.GetRepository(ctx, name)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	refs := []string{"HEAD"}
+	if opts.AllBranches {
+		branches, err := api.GetBranches(ctx, name)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		refs = refs[:0]
+		for _, branch := range branches {
+			refs = append(refs, branch.Name)
+		}
+	}
+	old := map[string]RefBackup{}
+	if previous != nil {
+		for _, ref := range previous.Refs {
+			old[ref.Ref] = ref
+		}
+	}
+	backup := RepositoryBackup{Repository: repo}
+	var downloaded, unchanged []string
+	for _, ref := range refs {
+		log, err := api.GetLog(ctx, name, ref, "", 1)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if len(log.Commits) == 0 {
+			// Empty repository, nothing to archive.
+			continue
+		}
+		head := log.Commits[0]
+		file := filepath.ToSlash(filepath.Join(name, ref+"."+string(opts.Format)))
+		if prev, ok := old[ref]; ok && prev.Head.Ref == head.Ref && prev.File == file {
+			if _, err := os.Stat(filepath.Join(opts.Dir, file)); err == nil {
+				backup.Refs = append(backup.Refs, prev)
+				unchanged = append(unchanged, name+"@"+ref)
+				continue
+			}
+		}
+		refBackup, err := downloadArchive(ctx, api, name, head.Ref, opts, file)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		refBackup.Ref = ref
+		refBackup.Head = head
+		backup.Refs = append(backup.Refs, refBackup)
+		downloaded = append(downloaded, name+"@"+ref)
+	}
+	return &backup, downloaded, unchanged, nil
+}
+
+// downloadArchive writes the archive of the commit to file, relative to
+// the backup directory. The archive is downloaded by commit, rather than
+// by ref, so it matches the head recorded in the manifest.
+func downloadArchive(ctx context.Context, api API, name, commit string, opts BackupOptions, file string) (RefBackup, error) {
+	path := filepath.Join(opts.Dir, filepath.FromSlash(file))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return RefBackup{}, err
+	}
+	archive, err := api.GetArchive(ctx, name, commit, opts.Format)
+	if err != nil {
+		return RefBackup{}, err
+	}
+	defer archive.Close()
+	hash := sha256.New()
+	size, err := atomicfile.WriteFile(path, io.TeeReader(archive, hash))
+	if err != nil {
+		return RefBackup{}, err
+	}
+	return RefBackup{
+		File:   file,
+		SHA256: hex.EncodeToString(hash.Sum(nil)),
+		Size:   size,
+		Time:   time.Now().UTC(),
+	}, nil
+}
+
+func readBackupManifest(dir string) (*BackupManifest, error) {
+	manifest := BackupManifest{Repositories: map[string]*RepositoryBackup{}}
+	data, err := ioutil.ReadFile(filepath.Join(dir, BackupManifestFile))
+	if os.IsNotExist(err) {
+		return &manifest, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid backup manifest: %s", err)
+	}
+	if manifest.Repositories == nil {
+		manifest.Repositories = map[string]*RepositoryBackup{}
+	}
+	return &manifest, nil
+}
+
+func writeBackupManifest(dir string, manifest *BackupManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = atomicfile.WriteFile(filepath.Join(dir, BackupManifestFile), bytes.NewReader(append(data, '\n')))
+	return err
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/check.v1"
+)
+
+// backupHandler serves repositories whose heads are set in heads, counting
+// the archives downloaded.
+type backupHandler struct {
+	mu       sync.Mutex
+	heads    map[string]string
+	archives []string
+}
+
+func (h *backupHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/repository/"), "/")
+	head, ok := h.heads[parts[0]]
+	if !ok {
+		http.Error(w, "repository not found", http.StatusNotFound)
+		return
+	}
+	switch {
+	case len(parts) == 1:
+		fmt.Fprintf(w, `{"name":%q,"git_url":"git@host:%s.git"}`, parts[0], parts[0])
+	case parts[1] == "logs":
+		fmt.Fprintf(w, `{"commits":[{"ref":%q,"subject":"head"}]}`, head)
+	case parts[1] == "branches":
+		fmt.Fprint(w, `[{"name":"master"},{"name":"feature"}]`)
+	case parts[1] == "archive":
+		h.archives = append(h.archives, parts[0]+"@"+r.URL.Query().Get("ref"))
+		fmt.Fprintf(w, "archive of %s", r.URL.Query().Get("ref"))
+	}
+}
+
+func (s *S) TestBackup(c *check.C) {
+	h := backupHandler{heads: map[string]string{"foo": "aaa", "bar": "bbb"}}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	dir := c.MkDir()
+	report, err := Backup(ctx, &client, []string{"foo", "bar"}, BackupOptions{Dir: dir, Concurrency: 2})
+	c.Assert(err, check.IsNil)
+	c.Assert(report.Downloaded, check.DeepEquals, []string{"bar@HEAD", "foo@HEAD"})
+	c.Assert(report.Unchanged, check.HasLen, 0)
+	data, err := ioutil.ReadFile(filepath.Join(dir, "foo", "HEAD.tar.gz"))
+	c.Assert(err, check.IsNil)
+	c.Assert(string(data), check.Equals, "archive of aaa")
+	sum := sha256.Sum256(data)
+	manifest, err := readBackupManifest(dir)
+	c.Assert(err, check.IsNil)
+	foo := manifest.Repositories["foo"]
+	c.Assert(foo.Repository.Name, check.Equals, "foo")
+	c.Assert(foo.Refs, check.HasLen, 1)
+	c.Assert(foo.Refs[0].Ref, check.Equals, "HEAD")
+	c.Assert(foo.Refs[0].Head.Ref, check.Equals, "aaa")
+	c.Assert(foo.Refs[0].File, check.Equals, "foo/HEAD.tar.gz")
+	c.Assert(foo.Refs[0].SHA256, check.Equals, hex.EncodeToString(sum[:]))
+	c.Assert(foo.Refs[0].Size, check.Equals, int64(len(data)))
+}
+
+func (s *S) TestBackupResume(c *check.C) {
+	h := backupHandler{heads: map[string]string{"foo": "aaa", "bar": "bbb"}}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	dir := c.MkDir()
+	_, err := Backup(ctx, &client, []string{"foo", "bar"}, BackupOptions{Dir: dir})
+	c.Assert(err, check.IsNil)
+	h.heads["bar"] = "ccc"
+	h.archives = nil
+	report, err := Backup(ctx, &client, []string{"foo", "bar"}, BackupOptions{Dir: dir})
+	c.Assert(err, check.IsNil)
+	c.Assert(report.Downloaded, check.DeepEquals, []string{"bar@HEAD"})
+	c.Assert(report.Unchanged, check.DeepEquals, []string{"foo@HEAD"})
+	c.Assert(h.archives, check.DeepEquals, []string{"bar@ccc"})
+	c.Assert(report.Manifest.Repositories["bar"].Refs[0].Head.Ref, check.Equals, "ccc")
+}
+
+func (s *S) TestBackupAllBranches(c *check.C) {
+	h := backupHandler{heads: map[string]string{"foo": "aaa"}}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	dir := c.MkDir()
+	report, err := Backup(ctx, &client, []string{"foo"}, BackupOptions{Dir: dir, AllBranches: true, Format: Zip})
+	c.Assert(err, check.IsNil)
+	c.Assert(report.Downloaded, check.DeepEquals, []string{"foo@feature", "foo@master"})
+	_, err = ioutil.ReadFile(filepath.Join(dir, "foo", "master.zip"))
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestBackupKeepsFailedRepositories(c *check.C) {
+	h := backupHandler{heads: map[string]string{"foo": "aaa", "bar": "bbb"}}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	dir := c.MkDir()
+	_, err := Backup(ctx, &client, []string{"foo", "bar"}, BackupOptions{Dir: dir})
+	c.Assert(err, check.IsNil)
+	delete(h.heads, "bar")
+	report, err := Backup(ctx, &client, []string{"foo", "bar"}, BackupOptions{Dir: dir})
+	c.Assert(err, check.ErrorMatches, "backup failed for 1 repositories: bar: repository not found\n")
+	c.Assert(report.Errors, check.HasLen, 1)
+	manifest, err := readBackupManifest(dir)
+	c.Assert(err, check.IsNil)
+	c.Assert(manifest.Repositories["bar"].Refs[0].Head.Ref, check.Equals, "bbb")
+}
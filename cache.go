@@ -0,0 +1,286 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachedClient is a Client that caches the results of GetRepository and
+// ListKeys for TTL. Concurrent identical calls are collapsed into a single
+// request, whose result is shared by all callers.
+//
+// Entries are invalidated when the same CachedClient changes the
+// repository or user they describe, as in GrantAccess or AddKey. Changes
+// made by other clients are only seen once the entries expire.
+//
+// Calls with options bypass the cache, as options such as
+// CaptureResponse need a request of their own.
+//
+// The shared request keeps the values of the context of the call that
+// started it, but not its deadline or cancellation, so callers that give
+// up do not fail the others. It is cancelled once all the callers waiting
+// for it give up, and the next call sends a new request.
+type CachedClient struct {
+	*Client
+	TTL time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	flights map[string]*flight
+	sweepAt int
+}
+
+var _ API = &CachedClient{}
+
+type cacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+type flight struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+
+	// invalidated is set when the key is invalidated while the request is
+	// in flight, so its result, possibly stale, is not cached.
+	invalidated bool
+
+	// waiters counts the callers waiting for the result, and cancel stops
+	// the request once there are none left.
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// detachedContext keeps the values of a context, but not its deadline or
+// cancellation.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+// NewCachedClient returns a CachedClient that caches the results of client
+// for ttl.
+func NewCachedClient(client *Client, ttl time.Duration) *CachedClient {
+	return &CachedClient{Client: client, TTL: ttl}
+}
+
+func repositoryKey(name string) string {
+	return "repository:" + name
+}
+
+func userKeysKey(name string) string {
+	return "keys:" + name
+}
+
+// fetch returns the cached value of key, or the result of fn, which is
+// called once for all concurrent callers. Each caller stops waiting when
+// its own ctx is done, and the last one to do so cancels the call.
+func (c *CachedClient) fetch(ctx context.Context, key string, fn func(context.Context) (interface{}, error)) (interface{}, error) {
+	c.mu.Lock()
+	if entry, ok := c.entries[key]; ok && time.Now().Before(entry.expires) {
+		c.mu.Unlock()
+		return entry.value, nil
+	}
+	f, ok := c.flights[key]
+	if !ok {
+		runCtx, cancel := context.WithCancel(detachedContext{ctx})
+		f = &flight{done: make(chan struct{}), cancel: cancel}
+		if c.flights == nil {
+			c.flights = make(map[string]*flight)
+		}
+		c.flights[key] = f
+		go c.run(runCtx, key, f, fn)
+	}
+	f.waiters++
+	c.mu.Unlock()
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		c.mu.Lock()
+		f.waiters--
+		if f.waiters == 0 {
+			f.cancel()
+			if c.flights[key] == f {
+				delete(c.flights, key)
+			}
+		}
+		c.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+func (c *CachedClient) run(ctx context.Context, key string, f *flight, fn func(context.Context) (interface{}, error)) {
+	value, err := fn(ctx)
+	f.cancel()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.flights[key] == f {
+		delete(c.flights, key)
+	}
+	if err == nil && c.TTL > 0 && !f.invalidated {
+		c.store(key, value)
+	}
+	f.value, f.err = value, err
+	close(f.done)
+}
+
+func (c *CachedClient) store(key string, value interface{}) {
+	now := time.Now()
+	if c.entries == nil {
+		c.entries = make(map[string]cacheEntry)
+	}
+	if len(c.entries) >= c.sweepAt {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		c.sweepAt = 2*len(c.entries) + 64
+	}
+	c.entries[key] = cacheEntry{value: value, expires: now.Add(c.TTL)}
+}
+
+// invalidate drops the cached values of the keys, and stops calls in
+// flight from sharing their results with new callers.
+func (c *CachedClient) invalidate(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		delete(c.entries, key)
+		if f, ok := c.flights[key]; ok {
+			f.invalidated = true
+			delete(c.flights, key)
+		}
+	}
+}
+
+// invalidatePrefix drops every key starting with prefix.
+func (c *CachedClient) invalidatePrefix(prefix string) {
+	c.mu.Lock()
+	var keys []string
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	for key := range c.flights {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	c.mu.Unlock()
+	c.invalidate(keys...)
+}
+
+// Purge drops all cached values.
+func (c *CachedClient) Purge() {
+	c.invalidatePrefix("")
+}
+
+func (c *CachedClient) GetRepository(ctx context.Context, name string, opts ...CallOption) (Repository, error) {
+	if len(opts) > 0 {
+		return c.Client.GetRepository(ctx, name, opts...)
+	}
+	value, err := c.fetch(ctx, repositoryKey(name), func(ctx context.Context) (interface{}, error) {
+		return c.Client.GetRepository(ctx, name)
+	})
+	if err != nil {
+		return Repository{}, err
+	}
+	r := value.(Repository)
+	r.Users = append([]string(nil), r.Users...)
+	r.ReadOnlyUsers = append([]string(nil), r.ReadOnlyUsers...)
+	return r, nil
+}
+
+func (c *CachedClient) ListKeys(ctx context.Context, uName string, opts ...CallOption) (map[string]string, error) {
+	if len(opts) > 0 {
+		return c.Client.ListKeys(ctx, uName, opts...)
+	}
+	value, err := c.fetch(ctx, userKeysKey(uName), func(ctx context.Context) (interface{}, error) {
+		return c.Client.ListKeys(ctx, uName)
+	})
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]string)
+	for k, v := range value.(map[string]string) {
+		keys[k] = v
+	}
+	return keys, nil
+}
+
+func (c *CachedClient) NewRepository(ctx context.Context, name string, users []string, isPublic bool, opts ...CallOption) (Repository, error) {
+	defer c.invalidate(repositoryKey(name))
+	return c.Client.NewRepository(ctx, name, users, isPublic, opts...)
+}
+
+func (c *CachedClient) RemoveRepository(ctx context.Context, name string, opts ...CallOption) error {
+	defer c.invalidate(repositoryKey(name))
+	return c.Client.RemoveRepository(ctx, name, opts...)
+}
+
+func (c *CachedClient) NewUser(ctx context.Context, name string, keys map[string]string, opts ...CallOption) (User, error) {
+	defer c.invalidate(userKeysKey(name))
+	return c.Client.NewUser(ctx, name, keys, opts...)
+}
+
+// RemoveUser removes the user and, as the server also removes it from the
+// repositories it had access to, drops all cached repositories.
+func (c *CachedClient) RemoveUser(ctx context.Context, name string, opts ...CallOption) error {
+	defer c.invalidatePrefix(repositoryKey(""))
+	defer c.invalidate(userKeysKey(name))
+	return c.Client.RemoveUser(ctx, name, opts...)
+}
+
+func (c *CachedClient) GrantAccess(ctx context.Context, rNames, uNames []string, opts ...CallOption) error {
+	defer c.invalidateRepositories(rNames)
+	return c.Client.GrantAccess(ctx, rNames, uNames, opts...)
+}
+
+func (c *CachedClient) RevokeAccess(ctx context.Context, rNames, uNames []string, opts ...CallOption) error {
+	defer c.invalidateRepositories(rNames)
+	return c.Client.RevokeAccess(ctx, rNames, uNames, opts...)
+}
+
+func (c *CachedClient) invalidateRepositories(names []string) {
+	keys := make([]string, len(names))
+	for i, name := range names {
+		keys[i] = repositoryKey(name)
+	}
+	c.invalidate(keys...)
+}
+
+func (c *CachedClient) AddKey(ctx context.Context, uName string, key map[string]string, opts ...CallOption) error {
+	defer c.invalidate(userKeysKey(uName))
+	return c.Client.AddKey(ctx, uName, key, opts...)
+}
+
+func (c *CachedClient) UpdateKey(ctx context.Context, uName, kName, kBody string, opts ...CallOption) error {
+	defer c.invalidate(userKeysKey(uName))
+	return c.Client.UpdateKey(ctx, uName, kName, kBody, opts...)
+}
+
+func (c *CachedClient) RemoveKey(ctx context.Context, uName, kName string, opts ...CallOption) error {
+	defer c.invalidate(userKeysKey(uName))
+	return c.Client.RemoveKey(ctx, uName, kName, opts...)
+}
@@ -0,0 +1,236 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+// countingHandler counts the requests per method and path, delaying GET
+// responses so concurrent calls overlap.
+type countingHandler struct {
+	mu     sync.Mutex
+	counts map[string]int
+	delay  time.Duration
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	if h.counts == nil {
+		h.counts = make(map[string]int)
+	}
+	h.counts[r.Method+" "+r.URL.Path]++
+	h.mu.Unlock()
+	if r.Method != http.MethodGet {
+		return
+	}
+	time.Sleep(h.delay)
+	if r.URL.Path == "/user/alice/keys" {
+		w.Write([]byte(`{"laptop":"ssh-ed25519 AAAA"}`))
+		return
+	}
+	w.Write([]byte(`{"name":"app","users":["alice"]}`))
+}
+
+func (h *countingHandler) count(req string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.counts[req]
+}
+
+func (s *S) TestCachedClientCollapsesConcurrentCalls(c *check.C) {
+	h := countingHandler{delay: 50 * time.Millisecond}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := NewCachedClient(&Client{Endpoint: ts.URL}, time.Minute)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			repo, err := client.GetRepository(ctx, "app")
+			c.Check(err, check.IsNil)
+			c.Check(repo.Users, check.DeepEquals, []string{"alice"})
+		}()
+	}
+	wg.Wait()
+	c.Assert(h.count("GET /repository/app"), check.Equals, 1)
+	_, err := client.GetRepository(ctx, "app")
+	c.Assert(err, check.IsNil)
+	c.Assert(h.count("GET /repository/app"), check.Equals, 1)
+}
+
+func (s *S) TestCachedClientExpires(c *check.C) {
+	h := countingHandler{}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := NewCachedClient(&Client{Endpoint: ts.URL}, 20*time.Millisecond)
+	_, err := client.ListKeys(ctx, "alice")
+	c.Assert(err, check.IsNil)
+	_, err = client.ListKeys(ctx, "alice")
+	c.Assert(err, check.IsNil)
+	c.Assert(h.count("GET /user/alice/keys"), check.Equals, 1)
+	time.Sleep(30 * time.Millisecond)
+	_, err = client.ListKeys(ctx, "alice")
+	c.Assert(err, check.IsNil)
+	c.Assert(h.count("GET /user/alice/keys"), check.Equals, 2)
+}
+
+func (s *S) TestCachedClientInvalidatesOnMutations(c *check.C) {
+	h := countingHandler{}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := NewCachedClient(&Client{Endpoint: ts.URL}, time.Minute)
+	client.GetRepository(ctx, "app")
+	client.GetRepository(ctx, "other")
+	client.ListKeys(ctx, "alice")
+	err := client.GrantAccess(ctx, []string{"app"}, []string{"bob"})
+	c.Assert(err, check.IsNil)
+	client.GetRepository(ctx, "app")
+	client.GetRepository(ctx, "other")
+	c.Assert(h.count("GET /repository/app"), check.Equals, 2)
+	c.Assert(h.count("GET /repository/other"), check.Equals, 1)
+	err = client.AddKey(ctx, "alice", map[string]string{"desktop": testKey})
+	c.Assert(err, check.IsNil)
+	client.ListKeys(ctx, "alice")
+	c.Assert(h.count("GET /user/alice/keys"), check.Equals, 2)
+	err = client.RemoveUser(ctx, "alice")
+	c.Assert(err, check.IsNil)
+	client.GetRepository(ctx, "other")
+	client.ListKeys(ctx, "alice")
+	c.Assert(h.count("GET /repository/other"), check.Equals, 2)
+	c.Assert(h.count("GET /user/alice/keys"), check.Equals, 3)
+}
+
+func (s *S) TestCachedClientReturnsCopies(c *check.C) {
+	h := countingHandler{}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := NewCachedClient(&Client{Endpoint: ts.URL}, time.Minute)
+	keys, err := client.ListKeys(ctx, "alice")
+	c.Assert(err, check.IsNil)
+	keys["changed"] = "value"
+	keys, err = client.ListKeys(ctx, "alice")
+	c.Assert(err, check.IsNil)
+	c.Assert(keys, check.DeepEquals, map[string]string{"laptop": "ssh-ed25519 AAAA"})
+}
+
+func (s *S) TestCachedClientBypassesCacheWithOptions(c *check.C) {
+	h := countingHandler{}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := NewCachedClient(&Client{Endpoint: ts.URL}, time.Minute)
+	client.GetRepository(ctx, "app")
+	var meta ResponseMeta
+	_, err := client.GetRepository(ctx, "app", CaptureResponse(&meta))
+	c.Assert(err, check.IsNil)
+	c.Assert(meta.StatusCode, check.Equals, http.StatusOK)
+	c.Assert(h.count("GET /repository/app"), check.Equals, 2)
+}
+
+func (s *S) TestCachedClientDoesNotCacheStaleResults(c *check.C) {
+	h := countingHandler{delay: 50 * time.Millisecond}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := NewCachedClient(&Client{Endpoint: ts.URL}, time.Minute)
+	done := make(chan struct{})
+	go func() {
+		client.GetRepository(ctx, "app")
+		close(done)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	err := client.RevokeAccess(ctx, []string{"app"}, []string{"alice"})
+	c.Assert(err, check.IsNil)
+	<-done
+	client.GetRepository(ctx, "app")
+	c.Assert(h.count("GET /repository/app"), check.Equals, 2)
+}
+
+func (s *S) TestCachedClientIgnoresCancellationOfFirstCaller(c *check.C) {
+	h := countingHandler{delay: 50 * time.Millisecond}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := NewCachedClient(&Client{Endpoint: ts.URL}, time.Minute)
+	first, cancel := context.WithCancel(ctx)
+	done := make(chan error)
+	go func() {
+		_, err := client.GetRepository(first, "app")
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	waiting := make(chan error)
+	go func() {
+		_, err := client.GetRepository(ctx, "app")
+		waiting <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	c.Assert(<-done, check.Equals, context.Canceled)
+	c.Assert(<-waiting, check.IsNil)
+	c.Assert(h.count("GET /repository/app"), check.Equals, 1)
+	_, err := client.GetRepository(ctx, "app")
+	c.Assert(err, check.IsNil)
+	c.Assert(h.count("GET /repository/app"), check.Equals, 1)
+}
+
+func (s *S) TestCachedClientDropsInvalidatedKeys(c *check.C) {
+	h := countingHandler{}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := NewCachedClient(&Client{Endpoint: ts.URL}, time.Minute)
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("app%d", i)
+		client.GetRepository(ctx, name)
+		client.RemoveRepository(ctx, name)
+	}
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	c.Assert(client.entries, check.HasLen, 0)
+	c.Assert(client.flights, check.HasLen, 0)
+}
+
+func (s *S) TestCachedClientCancelsAbandonedRequests(c *check.C) {
+	release := make(chan struct{})
+	var (
+		mu       sync.Mutex
+		requests int
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		first := requests == 1
+		mu.Unlock()
+		if first {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+			return
+		}
+		w.Write([]byte(`{"name":"app","users":["alice"]}`))
+	}))
+	defer ts.Close()
+	defer close(release)
+	client := NewCachedClient(&Client{Endpoint: ts.URL}, time.Minute)
+	timeout, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err := client.GetRepository(timeout, "app")
+	c.Assert(err, check.Equals, context.DeadlineExceeded)
+	timeout, cancel = context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	repo, err := client.GetRepository(timeout, "app")
+	c.Assert(err, check.IsNil)
+	c.Assert(repo.Name, check.Equals, "app")
+	mu.Lock()
+	defer mu.Unlock()
+	c.Assert(requests, check.Equals, 2)
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// CallOption customizes a single call of a Client method, as in:
+//
+//	diff, err := client.GetDiff(ctx, repo, from, to, gandalf.WithTimeout(time.Minute))
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	header   http.Header
+	timeout  time.Duration
+	response *ResponseMeta
+}
+
+// ResponseMeta is the metadata of a response, captured with
+// CaptureResponse.
+type ResponseMeta struct {
+	StatusCode int
+	Header     http.Header
+	RequestID  string
+
+	// Method and Path identify the request, without its query string.
+	Method string
+	Path   string
+
+	// Duration is the time between sending the request and receiving
+	// the headers of the response.
+	Duration time.Duration
+}
+
+type callOptionsKey struct{}
+
+// WithHeader adds a header to the requests sent by the call. It is added
+// after the headers of the Client, so it takes precedence over them.
+func WithHeader(key, value string) CallOption {
+	return func(o *callOptions) {
+		if o.header == nil {
+			o.header = make(http.Header)
+		}
+		o.header.Add(key, value)
+	}
+}
+
+// WithTimeout limits the time of each request sent by the call, including
+// reading its response.
+func WithTimeout(timeout time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithIdempotencyKey sends the key in the Idempotency-Key header, so
+// servers, or proxies, that support it can tell retries of a request
+// apart from new requests.
+func WithIdempotencyKey(key string) CallOption {
+	return WithHeader("Idempotency-Key", key)
+}
+
+// CaptureResponse stores the metadata of the response in meta, for both
+// successful and failed calls. Calls that send several requests, such as
+// GetCommit, store the last response. The body is not captured. Calls
+// that never get a response, such as the mutating calls of a dry run
+// client, leave meta unchanged.
+func CaptureResponse(meta *ResponseMeta) CallOption {
+	return func(o *callOptions) {
+		o.response = meta
+	}
+}
+
+func (o callOptions) capture(response *http.Response, start time.Time) {
+	if o.response == nil {
+		return
+	}
+	*o.response = ResponseMeta{
+		StatusCode: response.StatusCode,
+		Header:     response.Header,
+		RequestID:  requestID(response.Header),
+		Duration:   time.Since(start),
+	}
+	if request := response.Request; request != nil {
+		o.response.Method = request.Method
+		o.response.Path = request.URL.Path
+	}
+}
+
+// withCallOptions returns a context carrying the options, on top of the
+// options already in ctx, which is returned as is when there are none.
+func withCallOptions(ctx context.Context, opts []CallOption) context.Context {
+	if len(opts) == 0 {
+		return ctx
+	}
+	o := callOptionsFrom(ctx)
+	o.header = o.header.Clone()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return context.WithValue(ctx, callOptionsKey{}, o)
+}
+
+func callOptionsFrom(ctx context.Context) callOptions {
+	o, _ := ctx.Value(callOptionsKey{}).(callOptions)
+	return o
+}
+
+// cancelBody releases the context of a request with a timeout once its
+// response is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestWithHeader(c *check.C) {
+	h := testHandler{content: `{"name":"app"}`}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL, Header: http.Header{"X-Team": {"default"}}}
+	_, err := client.GetRepository(ctx, "app", WithHeader("X-Team", "infra"), WithHeader("X-Trace", "1"))
+	c.Assert(err, check.IsNil)
+	c.Assert(h.header.Get("X-Team"), check.Equals, "infra")
+	c.Assert(h.header.Get("X-Trace"), check.Equals, "1")
+	_, err = client.GetRepository(ctx, "app")
+	c.Assert(err, check.IsNil)
+	c.Assert(h.header.Get("X-Team"), check.Equals, "default")
+	c.Assert(h.header.Get("X-Trace"), check.Equals, "")
+}
+
+func (s *S) TestWithIdempotencyKey(c *check.C) {
+	h := testHandler{}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	_, err := client.NewRepository(ctx, "app", []string{"alice"}, false, WithIdempotencyKey("create-app-1"))
+	c.Assert(err, check.IsNil)
+	c.Assert(h.header.Get("Idempotency-Key"), check.Equals, "create-app-1")
+}
+
+func (s *S) TestWithTimeout(c *check.C) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer ts.Close()
+	defer close(release)
+	client := Client{Endpoint: ts.URL}
+	start := time.Now()
+	_, err := client.GetDiff(ctx, "app", "a", "b", WithTimeout(50*time.Millisecond))
+	c.Assert(err, check.ErrorMatches, ".*context deadline exceeded.*")
+	c.Assert(time.Since(start) < time.Second, check.Equals, true)
+}
+
+func (s *S) TestWithTimeoutCoversStreamedBody(c *check.C) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("archive"))
+	}))
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	archive, err := client.GetArchive(ctx, "app", "master", Zip, WithTimeout(time.Second))
+	c.Assert(err, check.IsNil)
+	data, err := ioutil.ReadAll(archive)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(data), check.Equals, "archive")
+	c.Assert(archive.Close(), check.IsNil)
+}
+
+func (s *S) TestCallOptionsNested(c *check.C) {
+	inner := withCallOptions(withCallOptions(ctx, []CallOption{WithHeader("A", "1")}), []CallOption{WithHeader("B", "2")})
+	o := callOptionsFrom(inner)
+	c.Assert(o.header, check.DeepEquals, http.Header{"A": {"1"}, "B": {"2"}})
+	c.Assert(callOptionsFrom(ctx).header, check.IsNil)
+}
+
+func (s *S) TestCaptureResponse(c *check.C) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-42")
+		w.Header().Set("Server-Timing", "git;dur=12.5")
+		if r.Method == http.MethodDelete {
+			http.Error(w, "user not found", http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"laptop":"ssh-ed25519 AAAA"}`))
+	}))
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	var meta ResponseMeta
+	_, err := client.ListKeys(ctx, "alice", CaptureResponse(&meta))
+	c.Assert(err, check.IsNil)
+	c.Assert(meta.StatusCode, check.Equals, http.StatusOK)
+	c.Assert(meta.RequestID, check.Equals, "req-42")
+	c.Assert(meta.Header.Get("Server-Timing"), check.Equals, "git;dur=12.5")
+	c.Assert(meta.Method, check.Equals, "GET")
+	c.Assert(meta.Path, check.Equals, "/user/alice/keys")
+	c.Assert(meta.Duration > 0, check.Equals, true)
+	err = client.RemoveUser(ctx, "alice", CaptureResponse(&meta))
+	c.Assert(err, check.NotNil)
+	c.Assert(meta.StatusCode, check.Equals, http.StatusNotFound)
+	c.Assert(meta.Method, check.Equals, "DELETE")
+}
+
+func (s *S) TestCaptureResponseDryRun(c *check.C) {
+	client := Client{Endpoint: "http://localhost", DryRun: true}
+	var meta ResponseMeta
+	err := client.RemoveUser(ctx, "alice", CaptureResponse(&meta))
+	c.Assert(err, check.IsNil)
+	c.Assert(meta, check.DeepEquals, ResponseMeta{})
+}
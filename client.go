@@ -8,7 +8,6 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -16,23 +15,85 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
-var GitTimeFormat = "Mon Jan _2 15:04:05 2006 -0700"
-
 type Client struct {
+	// Endpoint is the address of the Gandalf server. Besides http and
+	// https URLs, it accepts the unix:///path/to/gandalf.sock form for
+	// servers listening on a Unix domain socket.
 	Endpoint string
 	Client   *http.Client
+
+	// Endpoints lists the addresses of Gandalf replicas. When set, it
+	// takes precedence over Endpoint and requests are distributed
+	// according to Policy. Reads fail over to the next replica when one
+	// cannot be reached, while mutations are only sent to one replica.
+	Endpoints []string
+
+	// Policy defines how requests are distributed among Endpoints.
+	Policy EndpointPolicy
+
+	// HealthCheckInterval is how often endpoints marked as down are
+	// probed. Defaults to 10 seconds.
+	HealthCheckInterval time.Duration
+
+	// DryRun makes the client record mutating requests in its Plan
+	// instead of sending them. Read requests still reach the server.
+	DryRun bool
+
+	// Audit receives an entry for every mutating call made by the client.
+	Audit AuditSink
+
+	// OnAuditError, when set, is called with the entries Audit fails to
+	// write, such as when its disk is full. The call itself has already
+	// been made, so its result is not affected.
+	OnAuditError func(entry AuditEntry, err error)
+
+	// TLS holds the TLS settings used to build the HTTP client when
+	// Client is nil.
+	TLS *TLSConfig
+
+	// MaxResponseSize limits the size of response bodies, defaulting to
+	// DefaultMaxResponseSize. Negative values disable the limit.
+	MaxResponseSize int64
+
+	// ResponseLimits overrides MaxResponseSize for the operations in it,
+	// keyed by method name, as in "GetDiff".
+	ResponseLimits map[string]int64
+
+	// Header holds headers sent with every request, such as the
+	// credentials of a proxy in front of the server.
+	Header http.Header
+
+	// DisableCompression stops the client from asking for gzip or
+	// deflate compressed responses.
+	DisableCompression bool
+
+	// GzipRequestsOver, when positive, makes the client gzip request
+	// bodies of at least this many bytes. The server must accept gzip
+	// encoded requests.
+	GzipRequestsOver int
+
+	mu          sync.Mutex
+	tlsClient   *http.Client
+	unixClients map[string]*http.Client
+	endpoints   map[string]*endpoint
+	down        map[string]bool
+	reads       uint64
+	closed      chan struct{}
+	plan        []PlannedRequest
 }
 
 // repository represents a git repository.
 type repository struct {
-	Name     string   `json:"name"`
-	Users    []string `json:"users"`
-	IsPublic bool     `json:"ispublic"`
-	SSHURL   string   `json:"ssh_url,omitempty"`
-	GitURL   string   `json:"git_url,omitempty"`
+	Name          string   `json:"name"`
+	Users         []string `json:"users"`
+	ReadOnlyUsers []string `json:"readonlyusers,omitempty"`
+	IsPublic      bool     `json:"ispublic"`
+	SSHURL        string   `json:"ssh_url,omitempty"`
+	GitURL        string   `json:"git_url,omitempty"`
 }
 
 // repository represents a git user.
@@ -42,72 +103,116 @@ type user struct {
 }
 
 type Author struct {
-	Name  string
-	Email string
-	Date  GitTime
+	Name  string  `json:"name"`
+	Email string  `json:"email"`
+	Date  GitTime `json:"date"`
 }
 
 type Commit struct {
-	Ref       string
-	Author    Author
-	Committer Author
-	Subject   string
-	CreatedAt GitTime
-	Parent    []string
+	Ref       string   `json:"ref"`
+	Author    Author   `json:"author"`
+	Committer Author   `json:"committer"`
+	Subject   string   `json:"subject"`
+	CreatedAt GitTime  `json:"createdAt"`
+	Parent    []string `json:"parent"`
 }
 
-type GitTime time.Time
-
-func (c *GitTime) UnmarshalJSON(raw []byte) error {
-	strRaw := string(raw)
-	if strRaw == `""` || strRaw == "null" {
-		return nil
-	}
-	t, err := time.Parse(`"`+GitTimeFormat+`"`, strRaw)
-	if err != nil {
-		t, err = time.Parse(`"`+time.RFC3339+`"`, strRaw)
-		if err != nil {
-			return err
-		}
-	}
-	*c = GitTime(t)
-	return nil
+type Log struct {
+	Commits []Commit `json:"commits"`
+	Next    string   `json:"next"`
 }
 
-type Log struct {
-	Commits []Commit
-	Next    string
+// connectionError is returned by doRequest when the server could not be
+// reached.
+type connectionError struct {
+	endpoint string
+	err      error
 }
 
-type HTTPError struct {
-	Code   int
-	Reason string
+func (e *connectionError) Error() string {
+	return fmt.Sprintf("Failed to connect to Gandalf server (%s) - %s", e.endpoint, e.err.Error())
 }
 
-func (e *HTTPError) Error() string {
-	return e.Reason
+func (e *connectionError) Unwrap() error {
+	return e.err
 }
 
 func (c *Client) doRequest(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
-	endpoint := strings.TrimRight(c.Endpoint, "/")
-	request, err := http.NewRequest(method, endpoint+path, body)
+	opts := callOptionsFrom(ctx)
+	start := time.Now()
+	if opts.timeout <= 0 {
+		response, err := c.dispatchRequest(ctx, method, path, body)
+		if err != nil {
+			return nil, err
+		}
+		opts.capture(response, start)
+		return response, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, opts.timeout)
+	response, err := c.dispatchRequest(ctx, method, path, body)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	opts.capture(response, start)
+	response.Body = &cancelBody{ReadCloser: response.Body, cancel: cancel}
+	return response, nil
+}
+
+func (c *Client) dispatchRequest(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	if len(c.Endpoints) > 0 {
+		return c.doFailoverRequest(ctx, method, path, body)
+	}
+	return c.doEndpointRequest(ctx, c.Endpoint, method, path, body)
+}
+
+func (c *Client) doEndpointRequest(ctx context.Context, rawEndpoint, method, path string, body io.Reader) (*http.Response, error) {
+	endpoint, err := c.parsedEndpoint(rawEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	body, encoding, err := c.compressBody(body)
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequest(method, endpoint.resolve(path), body)
 	if err != nil {
-		return nil, errors.New("invalid Gandalf endpoint")
+		return nil, errInvalidEndpoint
 	}
 	request = request.WithContext(ctx)
 	request.Close = true
 	if body != nil {
 		request.Header.Set("Content-Type", "application/json")
 	}
+	if encoding != "" {
+		request.Header.Set("Content-Encoding", encoding)
+	}
+	for k, v := range c.Header {
+		request.Header[k] = v
+	}
+	for k, v := range callOptionsFrom(ctx).header {
+		request.Header[k] = v
+	}
+	if !c.DisableCompression {
+		request.Header.Set("Accept-Encoding", acceptEncoding)
+	}
 
-	client := c.Client
-	if client == nil {
-		client = http.DefaultClient
+	var client *http.Client
+	if endpoint.socket != "" {
+		client, err = c.unixHTTPClient(endpoint.socket)
+	} else {
+		client, err = c.httpClient()
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	response, err := client.Do(request)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to connect to Gandalf server (%s) - %s", c.Endpoint, err.Error())
+		return nil, &connectionError{endpoint: rawEndpoint, err: err}
+	}
+	if err := decompressResponse(response); err != nil {
+		return nil, err
 	}
 	return response, nil
 }
@@ -132,27 +237,33 @@ func (c *Client) post(ctx context.Context, b interface{}, path string) error {
 	if err != nil {
 		return err
 	}
+	if c.DryRun {
+		c.addToPlan("POST", path, body.String())
+		return nil
+	}
 	response, err := c.doRequest(ctx, "POST", path, body)
 	if err != nil {
 		return err
 	}
 	defer response.Body.Close()
 	if response.StatusCode != 200 {
-		b, _ := ioutil.ReadAll(response.Body)
-		return &HTTPError{Code: response.StatusCode, Reason: string(b)}
+		return newHTTPError(response)
 	}
 	return nil
 }
 
 func (c *Client) put(ctx context.Context, b, path string) error {
+	if c.DryRun {
+		c.addToPlan("PUT", path, b)
+		return nil
+	}
 	response, err := c.doRequest(ctx, "PUT", path, strings.NewReader(b))
 	if err != nil {
 		return err
 	}
 	defer response.Body.Close()
 	if response.StatusCode != 200 {
-		b, _ := ioutil.ReadAll(response.Body)
-		return &HTTPError{Code: response.StatusCode, Reason: string(b)}
+		return newHTTPError(response)
 	}
 	return nil
 }
@@ -162,127 +273,261 @@ func (c *Client) delete(ctx context.Context, b interface{}, path string) error {
 	if err != nil {
 		return err
 	}
+	if c.DryRun {
+		c.addToPlan("DELETE", path, body.String())
+		return nil
+	}
 	response, err := c.doRequest(ctx, "DELETE", path, body)
 	if err != nil {
 		return err
 	}
 	defer response.Body.Close()
 	if response.StatusCode != 200 {
-		b, _ := ioutil.ReadAll(response.Body)
-		return &HTTPError{Code: response.StatusCode, Reason: string(b)}
+		return newHTTPError(response)
 	}
 	return err
 }
 
-func (c *Client) get(ctx context.Context, path string) ([]byte, error) {
+// getBody sends a GET request and returns the body of the response,
+// limited to the maximum size of the operation. The caller must close it.
+func (c *Client) getBody(ctx context.Context, op, path string) (io.ReadCloser, error) {
 	response, err := c.doRequest(ctx, "GET", path, nil)
 	if err != nil {
-		return []byte{}, &HTTPError{Code: 500, Reason: err.Error()}
+		return nil, &HTTPError{Code: 500, Reason: err.Error(), Method: "GET", Path: pathOf(path), Err: err}
 	}
-	defer response.Body.Close()
-	b, err := ioutil.ReadAll(response.Body)
 	if response.StatusCode != 200 {
-		return []byte{}, &HTTPError{Code: response.StatusCode, Reason: string(b)}
+		defer response.Body.Close()
+		return nil, newHTTPError(response)
+	}
+	limit := c.responseLimit(op)
+	return newLimitedBody(response.Body, op, limit, response.ContentLength), nil
+}
+
+func (c *Client) get(ctx context.Context, op, path string) ([]byte, error) {
+	body, err := c.getBody(ctx, op, path)
+	if err != nil {
+		return []byte{}, err
 	}
-	return b, err
+	defer body.Close()
+	return ioutil.ReadAll(body)
 }
 
 // NewRepository creates a new repository with a given name and,
 // grants access to a list of users
 // and defines whether the repository is public.
-func (c *Client) NewRepository(ctx context.Context, name string, users []string, isPublic bool) (repository, error) {
+func (c *Client) NewRepository(ctx context.Context, name string, users []string, isPublic bool, opts ...CallOption) (repository, error) {
+	ctx = withCallOptions(ctx, opts)
+	var args validator
+	args.repository("name", name)
+	args.users("users", users)
+	if err := args.err("NewRepository"); err != nil {
+		return repository{}, err
+	}
 	r := repository{Name: name, Users: users, IsPublic: isPublic}
-	if err := c.post(ctx, r, "/repository"); err != nil {
+	start := time.Now()
+	err := c.post(ctx, r, "/repository")
+	c.audit(start, "NewRepository", map[string]interface{}{"name": name, "users": users, "ispublic": isPublic}, err)
+	if err != nil {
 		return repository{}, err
 	}
 	return r, nil
 }
 
 // GetRepository gets metadata from a repository in Gandalf server.
-func (c *Client) GetRepository(ctx context.Context, name string) (repository, error) {
+func (c *Client) GetRepository(ctx context.Context, name string, opts ...CallOption) (repository, error) {
+	ctx = withCallOptions(ctx, opts)
+	var args validator
+	args.repository("name", name)
+	if err := args.err("GetRepository"); err != nil {
+		return repository{}, err
+	}
 	url := fmt.Sprintf("/repository/%s?:name=%s", name, name)
-	b, err := c.get(ctx, url)
+	body, err := c.getBody(ctx, "GetRepository", url)
 	if err != nil {
 		return repository{}, err
 	}
+	defer body.Close()
 	var r repository
-	if err := json.Unmarshal(b, &r); err != nil {
-		return repository{}, fmt.Errorf("Caught error decoding returned json: %s", err.Error())
+	if err := decodeJSON(body, &r); err != nil {
+		return repository{}, err
 	}
 	return r, nil
 }
 
 // NewUser creates a new user with her/his given keys.
-func (c *Client) NewUser(ctx context.Context, name string, keys map[string]string) (user, error) {
+func (c *Client) NewUser(ctx context.Context, name string, keys map[string]string, opts ...CallOption) (user, error) {
+	ctx = withCallOptions(ctx, opts)
+	var args validator
+	args.user("name", name)
+	args.keys("keys", keys)
+	if err := args.err("NewUser"); err != nil {
+		return user{}, err
+	}
 	u := user{Name: name, Keys: keys}
-	if err := c.post(ctx, u, "/user"); err != nil {
+	start := time.Now()
+	err := c.post(ctx, u, "/user")
+	c.audit(start, "NewUser", map[string]interface{}{"name": name, "keys": fingerprints(keys)}, err)
+	if err != nil {
 		return user{}, err
 	}
 	return u, nil
 }
 
 // RemoveUser removes a user.
-func (c *Client) RemoveUser(ctx context.Context, name string) error {
-	return c.delete(ctx, nil, "/user/"+name)
+func (c *Client) RemoveUser(ctx context.Context, name string, opts ...CallOption) error {
+	ctx = withCallOptions(ctx, opts)
+	var args validator
+	args.user("name", name)
+	if err := args.err("RemoveUser"); err != nil {
+		return err
+	}
+	start := time.Now()
+	err := c.delete(ctx, nil, "/user/"+name)
+	c.audit(start, "RemoveUser", map[string]interface{}{"name": name}, err)
+	return err
 }
 
 // RemoveRepository removes a repository.
-func (c *Client) RemoveRepository(ctx context.Context, name string) error {
-	return c.delete(ctx, nil, "/repository/"+name)
+func (c *Client) RemoveRepository(ctx context.Context, name string, opts ...CallOption) error {
+	ctx = withCallOptions(ctx, opts)
+	var args validator
+	args.repository("name", name)
+	if err := args.err("RemoveRepository"); err != nil {
+		return err
+	}
+	start := time.Now()
+	err := c.delete(ctx, nil, "/repository/"+name)
+	c.audit(start, "RemoveRepository", map[string]interface{}{"name": name}, err)
+	return err
 }
 
 // GrantAccess grants access to N users into N repositories.
-func (c *Client) GrantAccess(ctx context.Context, rNames, uNames []string) error {
+func (c *Client) GrantAccess(ctx context.Context, rNames, uNames []string, opts ...CallOption) error {
+	ctx = withCallOptions(ctx, opts)
+	var args validator
+	args.repositories("repositories", rNames)
+	args.users("users", uNames)
+	if err := args.err("GrantAccess"); err != nil {
+		return err
+	}
 	b := map[string][]string{"repositories": rNames, "users": uNames}
-	return c.post(ctx, b, "/repository/grant")
+	start := time.Now()
+	err := c.post(ctx, b, "/repository/grant")
+	c.audit(start, "GrantAccess", map[string]interface{}{"repositories": rNames, "users": uNames}, err)
+	return err
 }
 
 // RevokeAccess revokes access from N users from N repositories.
-func (c *Client) RevokeAccess(ctx context.Context, rNames, uNames []string) error {
+func (c *Client) RevokeAccess(ctx context.Context, rNames, uNames []string, opts ...CallOption) error {
+	ctx = withCallOptions(ctx, opts)
+	var args validator
+	args.repositories("repositories", rNames)
+	args.users("users", uNames)
+	if err := args.err("RevokeAccess"); err != nil {
+		return err
+	}
 	b := map[string][]string{"repositories": rNames, "users": uNames}
-	return c.delete(ctx, b, "/repository/revoke")
+	start := time.Now()
+	err := c.delete(ctx, b, "/repository/revoke")
+	c.audit(start, "RevokeAccess", map[string]interface{}{"repositories": rNames, "users": uNames}, err)
+	return err
 }
 
 // AddKey adds keys to the user.
-func (c *Client) AddKey(ctx context.Context, uName string, key map[string]string) error {
+func (c *Client) AddKey(ctx context.Context, uName string, key map[string]string, opts ...CallOption) error {
+	ctx = withCallOptions(ctx, opts)
+	var args validator
+	args.user("user", uName)
+	args.keys("keys", key)
+	if err := args.err("AddKey"); err != nil {
+		return err
+	}
 	url := fmt.Sprintf("/user/%s/key", uName)
-	return c.post(ctx, key, url)
+	start := time.Now()
+	err := c.post(ctx, key, url)
+	c.audit(start, "AddKey", map[string]interface{}{"user": uName, "keys": fingerprints(key)}, err)
+	return err
 }
 
-func (c *Client) UpdateKey(ctx context.Context, uName, kName, kBody string) error {
+func (c *Client) UpdateKey(ctx context.Context, uName, kName, kBody string, opts ...CallOption) error {
+	ctx = withCallOptions(ctx, opts)
+	var args validator
+	args.user("user", uName)
+	args.key("key", kName)
+	if err := args.err("UpdateKey"); err != nil {
+		return err
+	}
 	url := fmt.Sprintf("/user/%s/key/%s", uName, kName)
-	return c.put(ctx, kBody, url)
+	start := time.Now()
+	err := c.put(ctx, kBody, url)
+	c.audit(start, "UpdateKey", map[string]interface{}{"user": uName, "key": kName, "fingerprint": keyFingerprint(kBody)}, err)
+	return err
 }
 
 // RemoveKey removes the key from the user.
-func (c *Client) RemoveKey(ctx context.Context, uName, kName string) error {
+func (c *Client) RemoveKey(ctx context.Context, uName, kName string, opts ...CallOption) error {
+	ctx = withCallOptions(ctx, opts)
+	var args validator
+	args.user("user", uName)
+	args.key("key", kName)
+	if err := args.err("RemoveKey"); err != nil {
+		return err
+	}
 	url := fmt.Sprintf("/user/%s/key/%s", uName, kName)
-	return c.delete(ctx, nil, url)
+	start := time.Now()
+	err := c.delete(ctx, nil, url)
+	c.audit(start, "RemoveKey", map[string]interface{}{"user": uName, "key": kName}, err)
+	return err
 }
 
 // ListKeys retrieves all keys a given user has
-func (c *Client) ListKeys(ctx context.Context, uName string) (map[string]string, error) {
+func (c *Client) ListKeys(ctx context.Context, uName string, opts ...CallOption) (map[string]string, error) {
+	ctx = withCallOptions(ctx, opts)
+	var args validator
+	args.user("user", uName)
+	if err := args.err("ListKeys"); err != nil {
+		return nil, err
+	}
 	url := fmt.Sprintf("/user/%s/keys", uName)
-	resp, err := c.get(ctx, url)
+	body, err := c.getBody(ctx, "ListKeys", url)
 	if err != nil {
 		return nil, err
 	}
+	defer body.Close()
 	keys := map[string]string{}
-	err = json.Unmarshal(resp, &keys)
+	err = decodeJSON(body, &keys)
 	return keys, err
 }
 
 //GetDiff gets diff output between commits from a repository in Gandalf server.
-func (c *Client) GetDiff(ctx context.Context, repo, previousCommit, lastCommit string) (string, error) {
-	url := fmt.Sprintf("/repository/%s/diff/commits?:name=%s&previous_commit=%s&last_commit=%s", repo, repo, previousCommit, lastCommit)
-	diffOutput, err := c.get(ctx, url)
+func (c *Client) GetDiff(ctx context.Context, repo, previousCommit, lastCommit string, opts ...CallOption) (string, error) {
+	ctx = withCallOptions(ctx, opts)
+	var args validator
+	args.repository("repo", repo)
+	if err := args.err("GetDiff"); err != nil {
+		return "", err
+	}
+	v := url.Values{}
+	v.Set(":name", repo)
+	v.Set("previous_commit", previousCommit)
+	v.Set("last_commit", lastCommit)
+	u := fmt.Sprintf("/repository/%s/diff/commits?%s", repo, v.Encode())
+	body, err := c.getBody(ctx, "GetDiff", u)
 	if err != nil {
-		return "", fmt.Errorf("Caught error getting repository metadata: %s", err.Error())
+		return "", fmt.Errorf("Caught error getting repository metadata: %w", err)
 	}
-	return string(diffOutput), nil
+	defer body.Close()
+	diffOutput, err := ioutil.ReadAll(body)
+	return string(diffOutput), err
 }
 
-func (c *Client) GetLog(ctx context.Context, repo, ref, path string, total int) (Log, error) {
+func (c *Client) GetLog(ctx context.Context, repo, ref, path string, total int, opts ...CallOption) (Log, error) {
+	ctx = withCallOptions(ctx, opts)
+	var args validator
+	args.repository("repo", repo)
+	if err := args.err("GetLog"); err != nil {
+		return Log{}, err
+	}
 	v := url.Values{}
 	v.Set("ref", ref)
 	if path != "" {
@@ -293,17 +538,114 @@ func (c *Client) GetLog(ctx context.Context, repo, ref, path string, total int)
 	}
 	u := fmt.Sprintf("/repository/%s/logs?%s", repo, v.Encode())
 	var ret Log
-	output, err := c.get(ctx, u)
+	body, err := c.getBody(ctx, "GetLog", u)
 	if err != nil {
-		return ret, fmt.Errorf("Caught error getting repository log: %s", err.Error())
+		return ret, fmt.Errorf("Caught error getting repository log: %w", err)
 	}
-	err = json.Unmarshal(output, &ret)
+	defer body.Close()
+	err = decodeJSON(body, &ret)
 	return ret, err
 }
 
+// Branch is a branch of a repository, along with its last commit.
+type Branch struct {
+	Name      string  `json:"name"`
+	Ref       string  `json:"ref"`
+	Subject   string  `json:"subject"`
+	CreatedAt GitTime `json:"createdAt"`
+	Author    Author  `json:"author"`
+	Committer Author  `json:"committer"`
+}
+
+// GetBranches lists the branches of the repository.
+func (c *Client) GetBranches(ctx context.Context, repo string, opts ...CallOption) ([]Branch, error) {
+	ctx = withCallOptions(ctx, opts)
+	var args validator
+	args.repository("repo", repo)
+	if err := args.err("GetBranches"); err != nil {
+		return nil, err
+	}
+	body, err := c.getBody(ctx, "GetBranches", fmt.Sprintf("/repository/%s/branches", repo))
+	if err != nil {
+		return nil, fmt.Errorf("Caught error getting repository branches: %w", err)
+	}
+	defer body.Close()
+	var branches []Branch
+	err = decodeJSON(body, &branches)
+	return branches, err
+}
+
+// TreeEntry is a file in the tree of a repository.
+type TreeEntry struct {
+	FileType   string `json:"filetype"`
+	Hash       string `json:"hash"`
+	Path       string `json:"path"`
+	Permission string `json:"permission"`
+	RawPath    string `json:"rawPath"`
+}
+
+// GetTree lists the files of the repository at ref, optionally restricted
+// to the given path.
+func (c *Client) GetTree(ctx context.Context, repo, ref, path string, opts ...CallOption) ([]TreeEntry, error) {
+	ctx = withCallOptions(ctx, opts)
+	var args validator
+	args.repository("repo", repo)
+	if err := args.err("GetTree"); err != nil {
+		return nil, err
+	}
+	v := url.Values{}
+	v.Set("ref", ref)
+	if path != "" {
+		v.Set("path", path)
+	}
+	u := fmt.Sprintf("/repository/%s/tree?%s", repo, v.Encode())
+	body, err := c.getBody(ctx, "GetTree", u)
+	if err != nil {
+		return nil, fmt.Errorf("Caught error getting repository tree: %w", err)
+	}
+	defer body.Close()
+	var tree []TreeEntry
+	err = decodeJSON(body, &tree)
+	return tree, err
+}
+
+// ArchiveFormat is the format of a repository archive.
+type ArchiveFormat string
+
+const (
+	Zip   ArchiveFormat = "zip"
+	Tar   ArchiveFormat = "tar"
+	TarGz ArchiveFormat = "tar.gz"
+)
+
+// GetArchive returns the contents of the repository at ref as an archive
+// in the given format. The caller must close the returned reader.
+func (c *Client) GetArchive(ctx context.Context, repo, ref string, format ArchiveFormat, opts ...CallOption) (io.ReadCloser, error) {
+	ctx = withCallOptions(ctx, opts)
+	var args validator
+	args.repository("repo", repo)
+	if err := args.err("GetArchive"); err != nil {
+		return nil, err
+	}
+	v := url.Values{}
+	v.Set("ref", ref)
+	v.Set("format", string(format))
+	u := fmt.Sprintf("/repository/%s/archive?%s", repo, v.Encode())
+	response, err := c.doRequest(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != 200 {
+		defer response.Body.Close()
+		return nil, newHTTPError(response)
+	}
+	return newLimitedBody(response.Body, "GetArchive", c.responseLimit("GetArchive"), response.ContentLength), nil
+}
+
 //GetHealthCheck gets healthcheck request output in Gandalf server.
-func (c *Client) GetHealthCheck(ctx context.Context) ([]byte, error) {
-	result, err := c.get(ctx, "/healthcheck")
+func (c *Client) GetHealthCheck(ctx context.Context, opts ...CallOption) ([]byte, error) {
+	ctx = withCallOptions(ctx, opts)
+	result, err := c.get(ctx, "GetHealthCheck", "/healthcheck")
 	if err != nil {
 		return []byte{}, &HTTPError{Code: 500, Reason: err.Error()}
 	}
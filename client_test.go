@@ -9,7 +9,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"io/ioutil"
+	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"time"
 
 	"gopkg.in/check.v1"
@@ -177,7 +180,7 @@ func (s *S) TestGet(c *check.C) {
 	ts := httptest.NewServer(&h)
 	defer ts.Close()
 	client := Client{Endpoint: ts.URL}
-	out, err := client.get(ctx, "/user/someuser")
+	out, err := client.get(ctx, "GetUser", "/user/someuser")
 	c.Assert(err, check.IsNil)
 	c.Assert(string(out), check.Equals, `{"fookey": "bar keycontent"}`)
 	c.Assert(h.url, check.Equals, "/user/someuser")
@@ -189,7 +192,7 @@ func (s *S) TestGetWithError(c *check.C) {
 	ts := httptest.NewServer(&h)
 	defer ts.Close()
 	client := Client{Endpoint: ts.URL}
-	_, err := client.get(ctx, "/user/someuser")
+	_, err := client.get(ctx, "GetUser", "/user/someuser")
 	c.Assert(err, check.ErrorMatches, "^Error performing requested operation\n$")
 }
 
@@ -485,11 +488,25 @@ func (s *S) TestGetDiff(c *check.C) {
 	client := Client{Endpoint: ts.URL}
 	diffOutput, err := client.GetDiff(ctx, "repo-name", "1b970b076bbb30d708e262b402d4e31910e1dc10", "545b1904af34458704e2aa06ff1aaffad5289f8f")
 	c.Assert(err, check.IsNil)
-	c.Assert(h.url, check.Equals, "/repository/repo-name/diff/commits?:name=repo-name&previous_commit=1b970b076bbb30d708e262b402d4e31910e1dc10&last_commit=545b1904af34458704e2aa06ff1aaffad5289f8f")
+	c.Assert(h.url, check.Equals, "/repository/repo-name/diff/commits?%3Aname=repo-name&last_commit=545b1904af34458704e2aa06ff1aaffad5289f8f&previous_commit=1b970b076bbb30d708e262b402d4e31910e1dc10")
 	c.Assert(h.method, check.Equals, "GET")
 	c.Assert(diffOutput, check.Equals, content)
 }
 
+func (s *S) TestGetDiffEscapesRefs(c *check.C) {
+	var query url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+	}))
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	_, err := client.GetDiff(ctx, "repo-name", "fix&last_commit=evil", "release#1")
+	c.Assert(err, check.IsNil)
+	c.Assert(query.Get("previous_commit"), check.Equals, "fix&last_commit=evil")
+	c.Assert(query.Get("last_commit"), check.Equals, "release#1")
+	c.Assert(query.Get(":name"), check.Equals, "repo-name")
+}
+
 func (s *S) TestGetDiffOnHTTPError(c *check.C) {
 	content := `null`
 	h := errorHandler{content: content}
@@ -499,6 +516,9 @@ func (s *S) TestGetDiffOnHTTPError(c *check.C) {
 	_, err := client.GetDiff(ctx, "repo-name", "1b970b076bbb30d708e262b402d4e31910e1dc10", "545b1904af34458704e2aa06ff1aaffad5289f8f")
 	c.Assert(err, check.NotNil)
 	c.Assert(err, check.ErrorMatches, "^Caught error getting repository metadata: Error performing requested operation\n$")
+	var httpErr *HTTPError
+	c.Assert(errors.As(err, &httpErr), check.Equals, true)
+	c.Assert(httpErr.Code, check.Equals, http.StatusBadRequest)
 }
 
 func (s *S) TestHealthCheck(c *check.C) {
@@ -525,6 +545,22 @@ func (s *S) TestHealthCheckOnHTTPError(c *check.C) {
 	c.Assert(err, check.ErrorMatches, "^Error performing requested operation\n$")
 }
 
+func (s *S) TestGetLogOnHTTPError(c *check.C) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	_, err := client.GetLog(ctx, "repo-name", "master", "", 1)
+	c.Assert(err, check.ErrorMatches, "^Caught error getting repository log: unavailable\n$")
+	var httpErr *HTTPError
+	c.Assert(errors.As(err, &httpErr), check.Equals, true)
+	c.Assert(httpErr.Code, check.Equals, http.StatusServiceUnavailable)
+	c.Assert(httpErr.RequestID, check.Equals, "req-1")
+	c.Assert(httpErr.Temporary(), check.Equals, true)
+}
+
 func (s *S) TestGetLog(c *check.C) {
 	content := `
 	{
@@ -582,3 +618,71 @@ func (s *S) TestGetLog(c *check.C) {
 		}, Next: "75239a1976f92da9b39c24cdbfae4bfb473cd0e8",
 	})
 }
+
+func (s *S) TestGetTree(c *check.C) {
+	content := `[{"filetype":"blob","hash":"3333333","path":"README.md","permission":"100644","rawPath":"README.md"}]`
+	h := testHandler{content: content}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	tree, err := client.GetTree(ctx, "repo-name", "master", "docs")
+	c.Assert(err, check.IsNil)
+	c.Assert(h.url, check.Equals, "/repository/repo-name/tree?path=docs&ref=master")
+	c.Assert(h.method, check.Equals, "GET")
+	c.Assert(tree, check.DeepEquals, []TreeEntry{
+		{FileType: "blob", Hash: "3333333", Path: "README.md", Permission: "100644", RawPath: "README.md"},
+	})
+}
+
+func (s *S) TestGetTreeOnHTTPError(c *check.C) {
+	ts := httptest.NewServer(&errorHandler{})
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	_, err := client.GetTree(ctx, "repo-name", "master", "")
+	c.Assert(err, check.ErrorMatches, "^Caught error getting repository tree: Error performing requested operation\n$")
+}
+
+func (s *S) TestGetArchive(c *check.C) {
+	h := testHandler{content: "archive content"}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	archive, err := client.GetArchive(ctx, "repo-name", "master", TarGz)
+	c.Assert(err, check.IsNil)
+	defer archive.Close()
+	data, err := ioutil.ReadAll(archive)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(data), check.Equals, "archive content")
+	c.Assert(h.url, check.Equals, "/repository/repo-name/archive?format=tar.gz&ref=master")
+}
+
+func (s *S) TestGetArchiveOnHTTPError(c *check.C) {
+	ts := httptest.NewServer(&errorHandler{})
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	_, err := client.GetArchive(ctx, "repo-name", "master", Zip)
+	c.Assert(err, check.ErrorMatches, "^Error performing requested operation\n$")
+}
+
+func (s *S) TestGetBranches(c *check.C) {
+	content := `[{"name":"master","ref":"545b1904af34458704e2aa06ff1aaffad5289f8f","subject":"Improve README","createdAt":"Tue Dec 1 18:57:08 2015 -0200"}]`
+	h := testHandler{content: content}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	branches, err := client.GetBranches(ctx, "repo-name")
+	c.Assert(err, check.IsNil)
+	c.Assert(h.url, check.Equals, "/repository/repo-name/branches")
+	c.Assert(branches, check.HasLen, 1)
+	c.Assert(branches[0].Name, check.Equals, "master")
+	c.Assert(branches[0].Ref, check.Equals, "545b1904af34458704e2aa06ff1aaffad5289f8f")
+	c.Assert(branches[0].CreatedAt.String(), check.Equals, "Tue Dec  1 18:57:08 2015 -0200")
+}
+
+func (s *S) TestGetBranchesOnHTTPError(c *check.C) {
+	ts := httptest.NewServer(&errorHandler{})
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	_, err := client.GetBranches(ctx, "repo-name")
+	c.Assert(err, check.ErrorMatches, "^Caught error getting repository branches: Error performing requested operation\n$")
+}
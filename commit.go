@@ -0,0 +1,188 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// EmptyTree is the hash of git's empty tree, used to diff root commits.
+const EmptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// FileStat holds the lines changed in a file by a commit.
+type FileStat struct {
+	Path string `json:"path"`
+
+	// OldPath is the previous path of renamed files.
+	OldPath string `json:"oldPath,omitempty"`
+
+	Added   int  `json:"added"`
+	Removed int  `json:"removed"`
+	Binary  bool `json:"binary,omitempty"`
+}
+
+// CommitDetail is a commit along with its full message and the files it
+// changed.
+type CommitDetail struct {
+	Commit
+	Message  string              `json:"message"`
+	Trailers map[string][]string `json:"trailers,omitempty"`
+	Files    []FileStat          `json:"files"`
+}
+
+// logEntry is a commit as returned by the logs endpoint, including the
+// message fields reported by some servers.
+type logEntry struct {
+	Commit
+	Message string `json:"message"`
+	Body    string `json:"body"`
+}
+
+// GetCommit returns the commit that ref points to in the repository, with
+// the lines added and removed in each file, compared to its first parent.
+//
+// The full message is only available when the server reports it in the
+// commit log; otherwise the message holds just the subject.
+func (c *Client) GetCommit(ctx context.Context, repo, ref string, opts ...CallOption) (CommitDetail, error) {
+	ctx = withCallOptions(ctx, opts)
+	var args validator
+	args.repository("repo", repo)
+	if err := args.err("GetCommit"); err != nil {
+		return CommitDetail{}, err
+	}
+	v := url.Values{}
+	v.Set("ref", ref)
+	v.Set("total", "1")
+	output, err := c.get(ctx, "GetCommit", fmt.Sprintf("/repository/%s/logs?%s", repo, v.Encode()))
+	if err != nil {
+		return CommitDetail{}, fmt.Errorf("Caught error getting repository log: %w", err)
+	}
+	var log struct {
+		Commits []logEntry `json:"commits"`
+	}
+	if err := json.Unmarshal(output, &log); err != nil {
+		return CommitDetail{}, fmt.Errorf("Caught error decoding returned json: %s", err.Error())
+	}
+	if len(log.Commits) == 0 {
+		return CommitDetail{}, fmt.Errorf("commit %q not found in repository %q", ref, repo)
+	}
+	entry := log.Commits[0]
+	detail := CommitDetail{Commit: entry.Commit, Message: entry.Message}
+	if detail.Message == "" {
+		detail.Message = entry.Subject
+		if entry.Body != "" {
+			detail.Message += "\n\n" + entry.Body
+		}
+	}
+	detail.Trailers = ParseTrailers(detail.Message)
+	previous := EmptyTree
+	if len(entry.Parent) > 0 {
+		previous = entry.Parent[0]
+	}
+	diff, err := c.GetDiff(ctx, repo, previous, entry.Ref)
+	if err != nil {
+		return CommitDetail{}, err
+	}
+	detail.Files = ParseDiffStats(diff)
+	return detail, nil
+}
+
+var trailerRegexp = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*):\s*(.*)$`)
+
+// ParseTrailers returns the trailers found in the last paragraph of a commit
+// message, such as "Signed-off-by: Name <email>". Continuation lines,
+// starting with whitespace, are appended to the previous value.
+func ParseTrailers(message string) map[string][]string {
+	message = strings.TrimRight(message, "\n")
+	paragraphs := strings.Split(message, "\n\n")
+	if len(paragraphs) < 2 {
+		return nil
+	}
+	trailers := map[string][]string{}
+	var last string
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		if last != "" && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			values := trailers[last]
+			values[len(values)-1] += " " + strings.TrimSpace(line)
+			continue
+		}
+		m := trailerRegexp.FindStringSubmatch(line)
+		if m == nil {
+			return nil
+		}
+		last = m[1]
+		trailers[last] = append(trailers[last], m[2])
+	}
+	return trailers
+}
+
+// ParseDiffStats counts the lines added and removed per file in a unified
+// diff generated by git, such as the output of GetDiff.
+func ParseDiffStats(diff string) []FileStat {
+	var (
+		stats  []FileStat
+		file   *FileStat
+		inHunk bool
+	)
+	scanner := bufio.NewScanner(strings.NewReader(diff))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "diff --git ") {
+			stats = append(stats, FileStat{Path: headerPath(line)})
+			file = &stats[len(stats)-1]
+			inHunk = false
+			continue
+		}
+		if file == nil {
+			continue
+		}
+		if inHunk {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				file.Added++
+			case strings.HasPrefix(line, "-"):
+				file.Removed++
+			case strings.HasPrefix(line, "@@"):
+			case strings.HasPrefix(line, " "), strings.HasPrefix(line, `\`), line == "":
+			default:
+				inHunk = false
+			}
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case strings.HasPrefix(line, "rename from "):
+			file.OldPath = strings.TrimPrefix(line, "rename from ")
+		case strings.HasPrefix(line, "rename to "):
+			file.Path = strings.TrimPrefix(line, "rename to ")
+		case strings.HasPrefix(line, "+++ b/"):
+			file.Path = strings.TrimPrefix(line, "+++ b/")
+		case strings.HasPrefix(line, "--- a/") && file.Path == "":
+			file.Path = strings.TrimPrefix(line, "--- a/")
+		case strings.HasPrefix(line, "Binary files ") || line == "GIT binary patch":
+			file.Binary = true
+		}
+	}
+	return stats
+}
+
+// headerPath extracts the destination path from a "diff --git a/x b/x"
+// line. It may be ambiguous when paths contain " b/", in which case the
+// path is later fixed by the "+++" or "rename to" lines.
+func headerPath(line string) string {
+	paths := strings.TrimPrefix(line, "diff --git ")
+	if i := strings.LastIndex(paths, " b/"); i >= 0 {
+		return paths[i+3:]
+	}
+	return ""
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"gopkg.in/check.v1"
+)
+
+const testDiff = `diff --git a/README.md b/README.md
+index 1b970b0..545b190 100644
+--- a/README.md
++++ b/README.md
+@@ -1,3 +1,4 @@
+ Go Gandalf Client
+-is a client
++is a client package
++for Gandalf
+--- not a header
+ 
+diff --git a/old.go b/new.go
+similarity index 90%
+rename from old.go
+rename to new.go
+index 1111111..2222222 100644
+--- a/old.go
++++ b/new.go
+@@ -10,2 +10,2 @@ func main() {
+-	println("old")
++	println("new")
+diff --git a/logo.png b/logo.png
+new file mode 100644
+index 0000000..3333333
+Binary files /dev/null and b/logo.png differ
+diff --git a/removed.txt b/removed.txt
+deleted file mode 100644
+index 4444444..0000000
+--- a/removed.txt
++++ /dev/null
+@@ -1,2 +0,0 @@
+-line 1
+-line 2
+\ No newline at end of file
+`
+
+type commitHandler struct {
+	log  string
+	urls []string
+}
+
+func (h *commitHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.urls = append(h.urls, r.URL.String())
+	if r.URL.Path == "/repository/repo-name/logs" {
+		w.Write([]byte(h.log))
+		return
+	}
+	w.Write([]byte(testDiff))
+}
+
+func (s *S) TestGetCommit(c *check.C) {
+	h := commitHandler{log: `{"commits": [{
+		"ref": "545b1904af34458704e2aa06ff1aaffad5289f8f",
+		"author": {"name": "Joao Jose", "email": "joaojose@eu.com", "date": "Tue Dec 1 18:57:08 2015 -0200"},
+		"subject": "Improve README",
+		"body": "Explain what the client is for.\n\nSigned-off-by: Joao Jose <joaojose@eu.com>\nCo-authored-by: Maria <maria@eu.com>\nCo-authored-by: Jose\n  Silva <jose@eu.com>",
+		"createdAt": "Tue Dec 1 18:57:08 2015 -0200",
+		"parent": ["1b970b076bbb30d708e262b402d4e31910e1dc10"]
+	}], "next": "1b970b076bbb30d708e262b402d4e31910e1dc10"}`}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	commit, err := client.GetCommit(ctx, "repo-name", "master")
+	c.Assert(err, check.IsNil)
+	c.Assert(h.urls, check.DeepEquals, []string{
+		"/repository/repo-name/logs?ref=master&total=1",
+		"/repository/repo-name/diff/commits?%3Aname=repo-name&last_commit=545b1904af34458704e2aa06ff1aaffad5289f8f&previous_commit=1b970b076bbb30d708e262b402d4e31910e1dc10",
+	})
+	c.Assert(commit.Ref, check.Equals, "545b1904af34458704e2aa06ff1aaffad5289f8f")
+	c.Assert(commit.Subject, check.Equals, "Improve README")
+	c.Assert(commit.Author.Name, check.Equals, "Joao Jose")
+	c.Assert(commit.Message, check.Matches, "(?s)Improve README\n\nExplain what the client is for.\n\nSigned-off-by: .*")
+	c.Assert(commit.Trailers, check.DeepEquals, map[string][]string{
+		"Signed-off-by":  {"Joao Jose <joaojose@eu.com>"},
+		"Co-authored-by": {"Maria <maria@eu.com>", "Jose Silva <jose@eu.com>"},
+	})
+	c.Assert(commit.Files, check.DeepEquals, []FileStat{
+		{Path: "README.md", Added: 2, Removed: 2},
+		{Path: "new.go", OldPath: "old.go", Added: 1, Removed: 1},
+		{Path: "logo.png", Binary: true},
+		{Path: "removed.txt", Removed: 2},
+	})
+}
+
+func (s *S) TestGetCommitRootCommitWithoutBody(c *check.C) {
+	h := commitHandler{log: `{"commits": [{"ref": "1b970b076bbb30d708e262b402d4e31910e1dc10", "subject": "Initial commit"}]}`}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	commit, err := client.GetCommit(ctx, "repo-name", "1b970b076bbb30d708e262b402d4e31910e1dc10")
+	c.Assert(err, check.IsNil)
+	c.Assert(commit.Message, check.Equals, "Initial commit")
+	c.Assert(commit.Trailers, check.IsNil)
+	c.Assert(h.urls[1], check.Equals, "/repository/repo-name/diff/commits?%3Aname=repo-name&last_commit=1b970b076bbb30d708e262b402d4e31910e1dc10&previous_commit=4b825dc642cb6eb9a060e54bf8d69288fbee4904")
+}
+
+func (s *S) TestGetCommitNotFound(c *check.C) {
+	h := commitHandler{log: `{"commits": []}`}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	_, err := client.GetCommit(ctx, "repo-name", "missing")
+	c.Assert(err, check.ErrorMatches, `commit "missing" not found in repository "repo-name"`)
+}
+
+func (s *S) TestGetCommitOnHTTPError(c *check.C) {
+	ts := httptest.NewServer(&errorHandler{})
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	_, err := client.GetCommit(ctx, "repo-name", "master")
+	c.Assert(err, check.ErrorMatches, "^Caught error getting repository log: Error performing requested operation\n$")
+	var httpErr *HTTPError
+	c.Assert(errors.As(err, &httpErr), check.Equals, true)
+}
+
+func (s *S) TestParseTrailersRequiresTrailerParagraph(c *check.C) {
+	c.Assert(ParseTrailers("Fix bug\n\nSee: the docs\nfor details"), check.IsNil)
+	c.Assert(ParseTrailers("Fixes: #42"), check.IsNil)
+	c.Assert(ParseTrailers("Fix bug\n\nFixes: #42\n"), check.DeepEquals, map[string][]string{"Fixes": {"#42"}})
+}
@@ -0,0 +1,259 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+)
+
+const (
+	comparePageSize = 100
+	compareLimit    = 5000
+)
+
+// Comparison describes how two refs of a repository relate to each other.
+type Comparison struct {
+	Base      string `json:"base"`
+	Head      string `json:"head"`
+	MergeBase string `json:"mergeBase"`
+
+	// Ahead and Behind are the number of commits only reachable from
+	// Head and only reachable from Base, respectively.
+	Ahead  int `json:"ahead"`
+	Behind int `json:"behind"`
+
+	// AheadCommits and BehindCommits are the commits counted in Ahead
+	// and Behind, newest first.
+	AheadCommits  []Commit `json:"aheadCommits"`
+	BehindCommits []Commit `json:"behindCommits"`
+}
+
+// Compare compares the head ref against the base ref of the repository.
+//
+// When the server does not support comparing refs, the comparison is done
+// by walking the logs of both refs, newest first, following the parents of
+// their commits until the rest of the history is reachable from both. In
+// this mode, an error is returned if that takes more than 5000 commits on
+// each side.
+func (c *Client) Compare(ctx context.Context, repo, base, head string, opts ...CallOption) (Comparison, error) {
+	ctx = withCallOptions(ctx, opts)
+	var args validator
+	args.repository("repo", repo)
+	if err := args.err("Compare"); err != nil {
+		return Comparison{}, err
+	}
+	v := url.Values{}
+	v.Set("base", base)
+	v.Set("head", head)
+	output, err := c.get(ctx, "Compare", fmt.Sprintf("/repository/%s/compare?%s", repo, v.Encode()))
+	if err == nil {
+		var cmp Comparison
+		if err := json.Unmarshal(output, &cmp); err != nil {
+			return Comparison{}, fmt.Errorf("Caught error decoding returned json: %s", err.Error())
+		}
+		return cmp, nil
+	}
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || !compareUnsupported(httpErr.Code) {
+		return Comparison{}, err
+	}
+	return compareLogs(ctx, c, repo, base, head)
+}
+
+func compareUnsupported(code int) bool {
+	return code == http.StatusNotFound || code == http.StatusMethodNotAllowed || code == http.StatusNotImplemented
+}
+
+// logWalker pages through the log of a ref.
+type logWalker struct {
+	api  API
+	repo string
+	next string
+	read int
+	done bool
+}
+
+func newLogWalker(api API, repo, ref string) *logWalker {
+	return &logWalker{api: api, repo: repo, next: ref}
+}
+
+func (w *logWalker) fetch(ctx context.Context) ([]Commit, error) {
+	log, err := w.api.GetLog(ctx, w.repo, w.next, "", comparePageSize)
+	if err != nil {
+		return nil, err
+	}
+	w.read += len(log.Commits)
+	w.next = log.Next
+	w.done = log.Next == "" || len(log.Commits) == 0 || w.read >= compareLimit
+	return log.Commits, nil
+}
+
+// Flags of the commits in a commitGraph, telling which refs reach them.
+const (
+	fromHead = 1 << iota
+	fromBase
+	fromBoth = fromHead | fromBase
+)
+
+// commitGraph holds the commits read from the logs of two refs, and the
+// refs each commit is reachable from, following the parents of the
+// commits read so far.
+type commitGraph struct {
+	head, base string
+	commits    map[string]Commit
+	order      []string
+	flags      map[string]int
+}
+
+func (g *commitGraph) add(commits []Commit) {
+	for _, commit := range commits {
+		if _, ok := g.commits[commit.Ref]; ok {
+			continue
+		}
+		g.commits[commit.Ref] = commit
+		g.order = append(g.order, commit.Ref)
+	}
+}
+
+func (g *commitGraph) paint() {
+	g.flags = make(map[string]int)
+	for _, tip := range []struct {
+		ref  string
+		flag int
+	}{{g.head, fromHead}, {g.base, fromBase}} {
+		stack := []string{tip.ref}
+		for len(stack) > 0 {
+			ref := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if ref == "" || g.flags[ref]&tip.flag != 0 {
+				continue
+			}
+			g.flags[ref] |= tip.flag
+			stack = append(stack, g.commits[ref].Parent...)
+		}
+	}
+}
+
+// pending returns, sorted, the commits not read yet that are reachable
+// from only one of the refs. Until they are read, the commits only
+// reachable from the other ref are not known.
+func (g *commitGraph) pending() []string {
+	var refs []string
+	for ref, flags := range g.flags {
+		if _, ok := g.commits[ref]; !ok && flags != fromBoth {
+			refs = append(refs, ref)
+		}
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// mergeBase returns the first commit read, or else the first ref, that is
+// reachable from both refs and is not the parent of another such commit.
+func (g *commitGraph) mergeBase() string {
+	parents := make(map[string]bool)
+	var common []string
+	for ref, flags := range g.flags {
+		if flags != fromBoth {
+			continue
+		}
+		common = append(common, ref)
+		for _, parent := range g.commits[ref].Parent {
+			parents[parent] = true
+		}
+	}
+	for _, ref := range g.order {
+		if g.flags[ref] == fromBoth && !parents[ref] {
+			return ref
+		}
+	}
+	sort.Strings(common)
+	for _, ref := range common {
+		if !parents[ref] {
+			return ref
+		}
+	}
+	return ""
+}
+
+// compareLogs compares two refs using only the logs of the repository.
+//
+// Both logs are read until every commit not read yet is reachable from
+// both refs, so the commits reachable from only one of them are known.
+// This relies on the logs listing commits before their parents, as git
+// does. Paging a log only follows the history of the commit in Next, so
+// commits only reachable through other parents of merges are read with
+// logs of their own.
+func compareLogs(ctx context.Context, api API, repo, base, head string) (Comparison, error) {
+	baseLog := newLogWalker(api, repo, base)
+	headLog := newLogWalker(api, repo, head)
+	g := commitGraph{commits: make(map[string]Commit)}
+	requested := make(map[string]bool)
+	for {
+		var fetched bool
+		if !headLog.done {
+			commits, err := headLog.fetch(ctx)
+			if err != nil {
+				return Comparison{}, err
+			}
+			if g.head == "" && len(commits) > 0 {
+				g.head = commits[0].Ref
+			}
+			g.add(commits)
+			fetched = true
+		}
+		if !baseLog.done {
+			commits, err := baseLog.fetch(ctx)
+			if err != nil {
+				return Comparison{}, err
+			}
+			if g.base == "" && len(commits) > 0 {
+				g.base = commits[0].Ref
+			}
+			g.add(commits)
+			fetched = true
+		}
+		g.paint()
+		pending := g.pending()
+		if len(pending) == 0 {
+			break
+		}
+		if len(g.commits) >= 2*compareLimit {
+			return Comparison{}, fmt.Errorf("could not compare %q and %q within %d commits", base, head, compareLimit)
+		}
+		if fetched {
+			continue
+		}
+		for _, ref := range pending {
+			if requested[ref] {
+				return Comparison{}, fmt.Errorf("could not compare %q and %q: commit %s is missing from the logs", base, head, ref)
+			}
+			requested[ref] = true
+			log, err := api.GetLog(ctx, repo, ref, "", comparePageSize)
+			if err != nil {
+				return Comparison{}, err
+			}
+			g.add(log.Commits)
+		}
+	}
+	cmp := Comparison{Base: base, Head: head, MergeBase: g.mergeBase()}
+	for _, ref := range g.order {
+		switch g.flags[ref] {
+		case fromHead:
+			cmp.AheadCommits = append(cmp.AheadCommits, g.commits[ref])
+		case fromBase:
+			cmp.BehindCommits = append(cmp.BehindCommits, g.commits[ref])
+		}
+	}
+	cmp.Ahead = len(cmp.AheadCommits)
+	cmp.Behind = len(cmp.BehindCommits)
+	return cmp, nil
+}
@@ -0,0 +1,238 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+
+	"gopkg.in/check.v1"
+)
+
+// historyHandler serves the logs of a repository, without supporting the
+// compare endpoint. Logs list commits newest first, and, as in Gandalf,
+// their next page is the log of the first commit left out.
+type historyHandler struct {
+	parents  map[string][]string
+	order    map[string]int
+	branches map[string]string
+	requests int
+}
+
+func newHistoryHandler() *historyHandler {
+	return &historyHandler{parents: map[string][]string{}, order: map[string]int{}, branches: map[string]string{}}
+}
+
+// commit adds a commit with the given parents, newer than all the others.
+func (h *historyHandler) commit(ref string, parents ...string) string {
+	h.parents[ref] = parents
+	h.order[ref] = len(h.order)
+	return ref
+}
+
+// chain adds n commits named prefix1..prefixN on top of parent, returning
+// the last one.
+func (h *historyHandler) chain(parent, prefix string, n int) string {
+	for i := 1; i <= n; i++ {
+		ref := fmt.Sprintf("%s%d", prefix, i)
+		if parent == "" {
+			h.commit(ref)
+		} else {
+			h.commit(ref, parent)
+		}
+		parent = ref
+	}
+	return parent
+}
+
+func (h *historyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/repository/repo-name/logs" {
+		http.Error(w, "404 page not found", http.StatusNotFound)
+		return
+	}
+	h.requests++
+	ref := r.URL.Query().Get("ref")
+	if branch, ok := h.branches[ref]; ok {
+		ref = branch
+	}
+	total, _ := strconv.Atoi(r.URL.Query().Get("total"))
+	var reachable []string
+	seen := map[string]bool{}
+	for stack := []string{ref}; len(stack) > 0; {
+		ref := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		reachable = append(reachable, ref)
+		stack = append(stack, h.parents[ref]...)
+	}
+	sort.Slice(reachable, func(i, j int) bool {
+		return h.order[reachable[i]] > h.order[reachable[j]]
+	})
+	var log Log
+	for i, ref := range reachable {
+		if i == total {
+			log.Next = ref
+			break
+		}
+		log.Commits = append(log.Commits, Commit{Ref: ref, Subject: "commit " + ref, Parent: h.parents[ref]})
+	}
+	json.NewEncoder(w).Encode(log)
+}
+
+func refs(commits []Commit) []string {
+	var refs []string
+	for _, commit := range commits {
+		refs = append(refs, commit.Ref)
+	}
+	return refs
+}
+
+func (s *S) TestCompareFallsBackToLogs(c *check.C) {
+	h := newHistoryHandler()
+	common := h.chain("", "c", 3)
+	h.branches["master"] = h.chain(common, "m", 2)
+	h.branches["feature"] = h.chain(common, "f", 3)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	cmp, err := client.Compare(ctx, "repo-name", "master", "feature")
+	c.Assert(err, check.IsNil)
+	c.Assert(cmp.Base, check.Equals, "master")
+	c.Assert(cmp.Head, check.Equals, "feature")
+	c.Assert(cmp.MergeBase, check.Equals, "c3")
+	c.Assert(cmp.Ahead, check.Equals, 3)
+	c.Assert(cmp.Behind, check.Equals, 2)
+	c.Assert(refs(cmp.AheadCommits), check.DeepEquals, []string{"f3", "f2", "f1"})
+	c.Assert(refs(cmp.BehindCommits), check.DeepEquals, []string{"m2", "m1"})
+}
+
+func (s *S) TestCompareUpToDate(c *check.C) {
+	h := newHistoryHandler()
+	h.branches["master"] = h.chain("", "c", 3)
+	h.branches["feature"] = h.chain(h.branches["master"], "f", 1)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	cmp, err := client.Compare(ctx, "repo-name", "master", "feature")
+	c.Assert(err, check.IsNil)
+	c.Assert(cmp.MergeBase, check.Equals, "c3")
+	c.Assert(cmp.Ahead, check.Equals, 1)
+	c.Assert(cmp.Behind, check.Equals, 0)
+	cmp, err = client.Compare(ctx, "repo-name", "feature", "master")
+	c.Assert(err, check.IsNil)
+	c.Assert(cmp.Ahead, check.Equals, 0)
+	c.Assert(cmp.Behind, check.Equals, 1)
+}
+
+func (s *S) TestCompareWalksSeveralPages(c *check.C) {
+	h := newHistoryHandler()
+	common := h.chain("", "c", 200)
+	h.branches["master"] = h.chain(common, "m", 3)
+	h.branches["feature"] = h.chain(common, "f", 150)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	cmp, err := client.Compare(ctx, "repo-name", "master", "feature")
+	c.Assert(err, check.IsNil)
+	c.Assert(cmp.MergeBase, check.Equals, "c200")
+	c.Assert(cmp.Ahead, check.Equals, 150)
+	c.Assert(cmp.Behind, check.Equals, 3)
+	c.Assert(h.requests, check.Equals, 4)
+}
+
+func (s *S) TestCompareCountsOlderMergedCommits(c *check.C) {
+	h := newHistoryHandler()
+	common := h.chain("", "c", 3)
+	feature := h.commit("f1", common)
+	h.branches["master"] = h.commit("m1", common)
+	h.branches["feature"] = h.commit("x", feature, h.branches["master"])
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	cmp, err := client.Compare(ctx, "repo-name", "master", "feature")
+	c.Assert(err, check.IsNil)
+	c.Assert(cmp.MergeBase, check.Equals, "m1")
+	c.Assert(cmp.Ahead, check.Equals, 2)
+	c.Assert(cmp.Behind, check.Equals, 0)
+	c.Assert(refs(cmp.AheadCommits), check.DeepEquals, []string{"x", "f1"})
+}
+
+func (s *S) TestCompareCountsOlderCommitsMergedIntoBase(c *check.C) {
+	h := newHistoryHandler()
+	common := h.chain("", "c", 3)
+	side := h.commit("s1", common)
+	h.branches["feature"] = h.commit("f1", common)
+	master := h.commit("m1", common)
+	h.branches["master"] = h.commit("mm", master, side)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	cmp, err := client.Compare(ctx, "repo-name", "master", "feature")
+	c.Assert(err, check.IsNil)
+	c.Assert(cmp.MergeBase, check.Equals, "c3")
+	c.Assert(refs(cmp.AheadCommits), check.DeepEquals, []string{"f1"})
+	c.Assert(refs(cmp.BehindCommits), check.DeepEquals, []string{"mm", "m1", "s1"})
+	c.Assert(cmp.Behind, check.Equals, 3)
+}
+
+func (s *S) TestCompareReadsMergedBranchesAcrossPages(c *check.C) {
+	h := newHistoryHandler()
+	common := h.chain("", "c", 3)
+	side := h.chain(common, "s", 150)
+	main := h.chain(common, "m", 120)
+	h.branches["master"] = common
+	h.branches["feature"] = h.commit("x", main, side)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	cmp, err := client.Compare(ctx, "repo-name", "master", "feature")
+	c.Assert(err, check.IsNil)
+	c.Assert(cmp.MergeBase, check.Equals, "c3")
+	c.Assert(cmp.Ahead, check.Equals, 271)
+	c.Assert(cmp.Behind, check.Equals, 0)
+}
+
+func (s *S) TestCompareUnrelatedHistories(c *check.C) {
+	h := newHistoryHandler()
+	h.branches["master"] = h.chain("", "m", 2)
+	h.branches["pages"] = h.chain("", "p", 1)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	cmp, err := client.Compare(ctx, "repo-name", "master", "pages")
+	c.Assert(err, check.IsNil)
+	c.Assert(cmp.MergeBase, check.Equals, "")
+	c.Assert(refs(cmp.AheadCommits), check.DeepEquals, []string{"p1"})
+	c.Assert(refs(cmp.BehindCommits), check.DeepEquals, []string{"m2", "m1"})
+}
+
+func (s *S) TestCompareUsesServer(c *check.C) {
+	content := `{"base":"master","head":"feature","mergeBase":"c3","ahead":1,"behind":0,"aheadCommits":[{"ref":"f1"}]}`
+	h := testHandler{content: content}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	cmp, err := client.Compare(ctx, "repo-name", "master", "feature")
+	c.Assert(err, check.IsNil)
+	c.Assert(h.url, check.Equals, "/repository/repo-name/compare?base=master&head=feature")
+	c.Assert(cmp.MergeBase, check.Equals, "c3")
+	c.Assert(cmp.Ahead, check.Equals, 1)
+	c.Assert(refs(cmp.AheadCommits), check.DeepEquals, []string{"f1"})
+}
+
+func (s *S) TestCompareOnHTTPError(c *check.C) {
+	ts := httptest.NewServer(&errorHandler{})
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	_, err := client.Compare(ctx, "repo-name", "master", "feature")
+	c.Assert(err, check.ErrorMatches, "^Error performing requested operation\n$")
+}
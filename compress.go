@@ -0,0 +1,108 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// acceptEncoding is sent on every request, unless compression is
+// disabled. Setting it explicitly, rather than relying on the transport,
+// makes the negotiation work with custom transports and with proxies that
+// strip the transport's implicit header.
+const acceptEncoding = "gzip, deflate"
+
+// compressBody gzips the body of requests at least GzipRequestsOver bytes
+// long, returning the body to send and its encoding.
+func (c *Client) compressBody(body io.Reader) (io.Reader, string, error) {
+	if c.GzipRequestsOver <= 0 || body == nil {
+		return body, "", nil
+	}
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) < c.GzipRequestsOver {
+		return bytes.NewReader(data), "", nil
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, "", err
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return &buf, "gzip", nil
+}
+
+// decompressResponse replaces the body of compressed responses with its
+// decompressed content, as the transport does when it negotiates
+// compression itself. Responses that can't be decompressed are closed, and
+// reported as invalid rather than as connection errors, as the server was
+// reached.
+func decompressResponse(response *http.Response) error {
+	var (
+		body io.Reader
+		err  error
+	)
+	encoding := strings.ToLower(strings.TrimSpace(response.Header.Get("Content-Encoding")))
+	switch encoding {
+	case "gzip", "x-gzip":
+		body, err = gzip.NewReader(response.Body)
+	case "deflate":
+		body, err = newDeflateReader(response.Body)
+	default:
+		return nil
+	}
+	if err == io.EOF {
+		body, err = strings.NewReader(""), nil
+	}
+	if err != nil {
+		response.Body.Close()
+		return fmt.Errorf("invalid %s response from Gandalf server: %w", encoding, err)
+	}
+	response.Body = &decompressedBody{Reader: body, body: response.Body}
+	response.Header.Del("Content-Encoding")
+	response.Header.Del("Content-Length")
+	response.ContentLength = -1
+	response.Uncompressed = true
+	return nil
+}
+
+// newDeflateReader reads a deflate body, which should be zlib wrapped, but
+// is sent raw by some servers.
+func newDeflateReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+type decompressedBody struct {
+	io.Reader
+	body io.ReadCloser
+}
+
+func (b *decompressedBody) Close() error {
+	if closer, ok := b.Reader.(io.Closer); ok {
+		closer.Close()
+	}
+	return b.body.Close()
+}
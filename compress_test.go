@@ -0,0 +1,189 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gopkg.in/check.v1"
+)
+
+// compressingHandler responds with body, compressed with the given
+// encoding when the client accepts it.
+type compressingHandler struct {
+	body     []byte
+	encoding string
+
+	acceptEncoding  string
+	contentEncoding string
+	requestBody     []byte
+}
+
+func (h *compressingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.acceptEncoding = r.Header.Get("Accept-Encoding")
+	h.contentEncoding = r.Header.Get("Content-Encoding")
+	h.requestBody, _ = ioutil.ReadAll(r.Body)
+	if h.encoding == "" || !strings.Contains(h.acceptEncoding, h.encoding) && h.encoding != "raw-deflate" {
+		w.Write(h.body)
+		return
+	}
+	var cw io.WriteCloser
+	switch h.encoding {
+	case "gzip":
+		cw = gzip.NewWriter(w)
+	case "deflate":
+		cw = zlib.NewWriter(w)
+	case "raw-deflate":
+		cw, _ = flate.NewWriter(w, flate.DefaultCompression)
+	}
+	w.Header().Set("Content-Encoding", strings.TrimPrefix(h.encoding, "raw-"))
+	cw.Write(h.body)
+	cw.Close()
+}
+
+func (s *S) TestDecompressesResponses(c *check.C) {
+	diff := strings.Repeat("+added line\n-removed line\n", 1000)
+	for _, encoding := range []string{"", "gzip", "deflate", "raw-deflate"} {
+		h := compressingHandler{body: []byte(diff), encoding: encoding}
+		ts := httptest.NewServer(&h)
+		client := Client{Endpoint: ts.URL}
+		out, err := client.GetDiff(ctx, "repo", "a", "b")
+		ts.Close()
+		c.Check(err, check.IsNil, check.Commentf(encoding))
+		c.Check(out, check.Equals, diff, check.Commentf(encoding))
+		c.Check(h.acceptEncoding, check.Equals, "gzip, deflate")
+	}
+}
+
+func (s *S) TestDecompressedErrorBody(c *check.C) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(http.StatusNotFound)
+		gw := gzip.NewWriter(w)
+		gw.Write([]byte("repository not found"))
+		gw.Close()
+	}))
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	_, err := client.GetRepository(ctx, "repo")
+	c.Assert(err, check.ErrorMatches, "repository not found")
+}
+
+func (s *S) TestCorruptCompressedResponse(c *check.C) {
+	var requests int
+	corrupt := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write([]byte("not gzip"))
+	}))
+	defer corrupt.Close()
+	secondary := httptest.NewServer(&compressingHandler{body: []byte("diff")})
+	defer secondary.Close()
+	client := Client{Endpoints: []string{corrupt.URL, secondary.URL}}
+	_, err := client.GetDiff(ctx, "repo", "a", "b")
+	c.Assert(err, check.ErrorMatches, "Caught error getting repository metadata: invalid gzip response from Gandalf server: .*")
+	c.Assert(requests, check.Equals, 1)
+	c.Assert(client.down[corrupt.URL], check.Equals, false)
+	_, err = client.getBody(ctx, "GetLog", "/repository/repo/logs")
+	c.Assert(err, check.FitsTypeOf, &HTTPError{})
+	c.Assert(err.(*HTTPError).Temporary(), check.Equals, false)
+	c.Assert(requests, check.Equals, 2)
+}
+
+func (s *S) TestDisableCompression(c *check.C) {
+	h := compressingHandler{body: []byte(`{"commits":[]}`), encoding: "gzip"}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL, DisableCompression: true, Client: &http.Client{
+		Transport: &http.Transport{DisableCompression: true},
+	}}
+	_, err := client.GetLog(ctx, "repo", "master", "", 1)
+	c.Assert(err, check.IsNil)
+	c.Assert(h.acceptEncoding, check.Equals, "")
+}
+
+func (s *S) TestGzipRequests(c *check.C) {
+	h := compressingHandler{}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL, GzipRequestsOver: 100}
+	err := client.GrantAccess(ctx, []string{"repo"}, []string{"alice"})
+	c.Assert(err, check.IsNil)
+	c.Assert(h.contentEncoding, check.Equals, "")
+	users := make([]string, 50)
+	for i := range users {
+		users[i] = fmt.Sprintf("user%d", i)
+	}
+	err = client.GrantAccess(ctx, []string{"repo"}, users)
+	c.Assert(err, check.IsNil)
+	c.Assert(h.contentEncoding, check.Equals, "gzip")
+	gr, err := gzip.NewReader(bytes.NewReader(h.requestBody))
+	c.Assert(err, check.IsNil)
+	var body map[string][]string
+	err = json.NewDecoder(gr).Decode(&body)
+	c.Assert(err, check.IsNil)
+	c.Assert(body["users"], check.DeepEquals, users)
+}
+
+func benchmarkCompression(b *testing.B, body []byte, call func(client *Client) error) {
+	for _, encoding := range []string{"identity", "gzip"} {
+		b.Run(encoding, func(b *testing.B) {
+			h := compressingHandler{body: body, encoding: encoding}
+			if encoding == "identity" {
+				h.encoding = ""
+			}
+			ts := httptest.NewServer(&h)
+			defer ts.Close()
+			client := Client{Endpoint: ts.URL}
+			b.SetBytes(int64(len(body)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := call(&client); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGetDiffLarge(b *testing.B) {
+	var diff bytes.Buffer
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&diff, "diff --git a/file%d.go b/file%d.go\n@@ -1,3 +1,3 @@\n-old line %d\n+new line %d\n context\n", i, i, i, i)
+	}
+	benchmarkCompression(b, diff.Bytes(), func(client *Client) error {
+		_, err := client.GetDiff(context.Background(), "repo", "a", "b")
+		return err
+	})
+}
+
+func BenchmarkGetLogLarge(b *testing.B) {
+	var log Log
+	for i := 0; i < 1000; i++ {
+		log.Commits = append(log.Commits, Commit{
+			Ref:     fmt.Sprintf("%040d", i),
+			Author:  Author{Name: "Author", Email: "author@example.com"},
+			Subject: fmt.Sprintf("Change number %d", i),
+			Parent:  []string{fmt.Sprintf("%040d", i+1)},
+		})
+	}
+	body, _ := json.Marshal(log)
+	benchmarkCompression(b, body, func(client *Client) error {
+		_, err := client.GetLog(context.Background(), "repo", "master", "", 1000)
+		return err
+	})
+}
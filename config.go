@@ -0,0 +1,284 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Environment variables that override the location of the configuration
+// file and the context in use.
+const (
+	ConfigEnv  = "GANDALF_CONFIG"
+	ContextEnv = "GANDALF_CONTEXT"
+)
+
+// Config holds the settings of the Gandalf servers a user talks to, each
+// in a named context, as in:
+//
+//	current-context: staging
+//	contexts:
+//	  staging:
+//	    endpoint: https://gandalf.staging.example.com
+//	    tls:
+//	      ca-file: staging-ca.pem
+//	    auth:
+//	      token-file: ~/.gandalf/staging-token
+//	    timeout: 30s
+//	  prod:
+//	    endpoints:
+//	      - https://gandalf1.example.com
+//	      - https://gandalf2.example.com
+//	    policy: round-robin-reads
+//
+// Relative file paths are resolved from the directory of the configuration
+// file.
+type Config struct {
+	CurrentContext string                    `yaml:"current-context"`
+	Contexts       map[string]*ConfigContext `yaml:"contexts"`
+
+	dir string
+}
+
+// ConfigContext holds the settings of a Gandalf server.
+type ConfigContext struct {
+	Endpoint  string   `yaml:"endpoint"`
+	Endpoints []string `yaml:"endpoints,omitempty"`
+
+	// Policy is either "primary-secondary", the default, or
+	// "round-robin-reads".
+	Policy string `yaml:"policy,omitempty"`
+
+	TLS  *ConfigTLS  `yaml:"tls,omitempty"`
+	Auth *ConfigAuth `yaml:"auth,omitempty"`
+
+	// Timeout limits the time of each request, including reading the
+	// response body. Zero means no timeout.
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+
+	// HealthCheckInterval sets Client.HealthCheckInterval.
+	HealthCheckInterval time.Duration `yaml:"health-check-interval,omitempty"`
+
+	// MaxResponseSize sets Client.MaxResponseSize.
+	MaxResponseSize int64 `yaml:"max-response-size,omitempty"`
+}
+
+// ConfigTLS holds the TLS settings of a context, as in TLSConfig.
+type ConfigTLS struct {
+	CAFile   string `yaml:"ca-file,omitempty"`
+	CertFile string `yaml:"cert-file,omitempty"`
+	KeyFile  string `yaml:"key-file,omitempty"`
+
+	// MinVersion is "1.2" or "1.3".
+	MinVersion         string `yaml:"min-version,omitempty"`
+	ServerName         string `yaml:"server-name,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecure-skip-verify,omitempty"`
+}
+
+// ConfigAuth holds the credentials sent to the server, usually checked by
+// a proxy in front of it. Either a bearer token, read from Token or
+// TokenFile, or a username and password are used.
+type ConfigAuth struct {
+	Token     string `yaml:"token,omitempty"`
+	TokenFile string `yaml:"token-file,omitempty"`
+	Username  string `yaml:"username,omitempty"`
+	Password  string `yaml:"password,omitempty"`
+}
+
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// DefaultConfigPath returns the path in the GANDALF_CONFIG environment
+// variable or, when it is not set, gandalf/config.yaml in the user
+// configuration directory (~/.config/gandalf/config.yaml on Linux).
+func DefaultConfigPath() (string, error) {
+	if path := os.Getenv(ConfigEnv); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gandalf", "config.yaml"), nil
+}
+
+// LoadConfig reads the configuration file in the given path.
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config Config
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid Gandalf configuration in %s: %s", path, err)
+	}
+	config.dir = filepath.Dir(path)
+	return &config, nil
+}
+
+// NewClientFromConfig loads the configuration file in the default path and
+// builds a client for the named context, as Config.NewClient does.
+func NewClientFromConfig(name string) (*Client, error) {
+	path, err := DefaultConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	config, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return config.NewClient(name)
+}
+
+// NewClient builds a client for the named context. When name is empty, the
+// context in the GANDALF_CONTEXT environment variable is used, falling
+// back to the current context of the configuration.
+func (c *Config) NewClient(name string) (*Client, error) {
+	if name == "" {
+		name = os.Getenv(ContextEnv)
+	}
+	if name == "" {
+		name = c.CurrentContext
+	}
+	if name == "" {
+		return nil, errors.New("no Gandalf context selected")
+	}
+	cc, ok := c.Contexts[name]
+	if !ok || cc == nil {
+		return nil, fmt.Errorf("Gandalf context %q not found, available contexts: %s", name, strings.Join(c.ContextNames(), ", "))
+	}
+	client, err := cc.client(c.dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Gandalf context %q: %s", name, err)
+	}
+	return client, nil
+}
+
+// ContextNames returns the names of the contexts, sorted.
+func (c *Config) ContextNames() []string {
+	names := make([]string, 0, len(c.Contexts))
+	for name := range c.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (cc *ConfigContext) client(dir string) (*Client, error) {
+	if cc.Endpoint == "" && len(cc.Endpoints) == 0 {
+		return nil, errors.New("no endpoint set")
+	}
+	client := Client{
+		Endpoint:            cc.Endpoint,
+		Endpoints:           cc.Endpoints,
+		HealthCheckInterval: cc.HealthCheckInterval,
+		MaxResponseSize:     cc.MaxResponseSize,
+	}
+	switch cc.Policy {
+	case "", "primary-secondary":
+		client.Policy = PrimarySecondary
+	case "round-robin-reads":
+		client.Policy = RoundRobinReads
+	default:
+		return nil, fmt.Errorf("unknown policy %q", cc.Policy)
+	}
+	if cc.TLS != nil {
+		t, err := cc.TLS.config(dir)
+		if err != nil {
+			return nil, err
+		}
+		client.TLS = t
+	}
+	if cc.Auth != nil {
+		header, err := cc.Auth.header(dir)
+		if err != nil {
+			return nil, err
+		}
+		client.Header = header
+	}
+	if cc.Timeout > 0 {
+		httpClient := &http.Client{}
+		if client.TLS != nil {
+			var err error
+			if httpClient, err = newTLSClient(client.TLS); err != nil {
+				return nil, err
+			}
+		}
+		httpClient.Timeout = cc.Timeout
+		client.Client = httpClient
+	}
+	return &client, nil
+}
+
+func (t *ConfigTLS) config(dir string) (*TLSConfig, error) {
+	config := TLSConfig{
+		CAFile:             resolvePath(dir, t.CAFile),
+		CertFile:           resolvePath(dir, t.CertFile),
+		KeyFile:            resolvePath(dir, t.KeyFile),
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+	if t.MinVersion != "" {
+		version, ok := tlsVersions[t.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported TLS version %q", t.MinVersion)
+		}
+		config.MinVersion = version
+	}
+	return &config, nil
+}
+
+func (a *ConfigAuth) header(dir string) (http.Header, error) {
+	token := a.Token
+	if a.TokenFile != "" {
+		data, err := ioutil.ReadFile(resolvePath(dir, a.TokenFile))
+		if err != nil {
+			return nil, err
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	header := http.Header{}
+	switch {
+	case token != "" && a.Username != "":
+		return nil, errors.New("auth must have either a token or a username, not both")
+	case token != "":
+		header.Set("Authorization", "Bearer "+token)
+	case a.Username != "":
+		credentials := base64.StdEncoding.EncodeToString([]byte(a.Username + ":" + a.Password))
+		header.Set("Authorization", "Basic "+credentials)
+	}
+	return header, nil
+}
+
+// resolvePath expands a leading ~ to the home directory and makes relative
+// paths relative to dir.
+func resolvePath(dir, path string) string {
+	if path == "" {
+		return ""
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
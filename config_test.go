@@ -0,0 +1,112 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+const testConfig = `current-context: dev
+contexts:
+  dev:
+    endpoint: %s
+    auth:
+      token-file: token
+    timeout: 5s
+  prod:
+    endpoints:
+      - https://gandalf1.example.com
+      - https://gandalf2.example.com
+    policy: round-robin-reads
+    tls:
+      ca-file: certs/ca.pem
+      min-version: "1.3"
+    auth:
+      username: admin
+      password: secret
+    max-response-size: 1024
+`
+
+func writeConfig(c *check.C, endpoint string) string {
+	dir := c.MkDir()
+	path := filepath.Join(dir, "config.yaml")
+	content := []byte(fmt.Sprintf(testConfig, endpoint))
+	c.Assert(ioutil.WriteFile(path, content, 0600), check.IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "token"), []byte("s3cr3t\n"), 0600), check.IsNil)
+	return path
+}
+
+func (s *S) TestConfigCurrentContext(c *check.C) {
+	h := testHandler{content: `{"name":"app"}`}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	config, err := LoadConfig(writeConfig(c, ts.URL))
+	c.Assert(err, check.IsNil)
+	c.Assert(config.ContextNames(), check.DeepEquals, []string{"dev", "prod"})
+	client, err := config.NewClient("")
+	c.Assert(err, check.IsNil)
+	c.Assert(client.Endpoint, check.Equals, ts.URL)
+	c.Assert(client.Client.Timeout, check.Equals, 5*time.Second)
+	_, err = client.GetRepository(ctx, "app")
+	c.Assert(err, check.IsNil)
+	c.Assert(h.header.Get("Authorization"), check.Equals, "Bearer s3cr3t")
+}
+
+func (s *S) TestConfigNamedContext(c *check.C) {
+	path := writeConfig(c, "http://localhost")
+	config, err := LoadConfig(path)
+	c.Assert(err, check.IsNil)
+	client, err := config.NewClient("prod")
+	c.Assert(err, check.IsNil)
+	c.Assert(client.Endpoints, check.DeepEquals, []string{"https://gandalf1.example.com", "https://gandalf2.example.com"})
+	c.Assert(client.Policy, check.Equals, RoundRobinReads)
+	c.Assert(client.TLS, check.DeepEquals, &TLSConfig{
+		CAFile:     filepath.Join(filepath.Dir(path), "certs", "ca.pem"),
+		MinVersion: tls.VersionTLS13,
+	})
+	c.Assert(client.Header.Get("Authorization"), check.Equals, "Basic YWRtaW46c2VjcmV0")
+	c.Assert(client.MaxResponseSize, check.Equals, int64(1024))
+	c.Assert(client.Client, check.IsNil)
+}
+
+func (s *S) TestConfigContextFromEnvironment(c *check.C) {
+	config, err := LoadConfig(writeConfig(c, "http://localhost"))
+	c.Assert(err, check.IsNil)
+	os.Setenv(ContextEnv, "prod")
+	defer os.Unsetenv(ContextEnv)
+	client, err := config.NewClient("")
+	c.Assert(err, check.IsNil)
+	c.Assert(client.Endpoints, check.HasLen, 2)
+}
+
+func (s *S) TestConfigErrors(c *check.C) {
+	config, err := LoadConfig(writeConfig(c, "http://localhost"))
+	c.Assert(err, check.IsNil)
+	_, err = config.NewClient("staging")
+	c.Assert(err, check.ErrorMatches, `Gandalf context "staging" not found, available contexts: dev, prod`)
+	config.Contexts["prod"].Policy = "random"
+	_, err = config.NewClient("prod")
+	c.Assert(err, check.ErrorMatches, `invalid Gandalf context "prod": unknown policy "random"`)
+	path := filepath.Join(c.MkDir(), "config.yaml")
+	ioutil.WriteFile(path, []byte("contexts:\n  dev:\n    endpont: http://localhost\n"), 0600)
+	_, err = LoadConfig(path)
+	c.Assert(err, check.ErrorMatches, "(?s)invalid Gandalf configuration in .*endpont.*")
+}
+
+func (s *S) TestNewClientFromConfig(c *check.C) {
+	os.Setenv(ConfigEnv, writeConfig(c, "http://gandalf.dev"))
+	defer os.Unsetenv(ConfigEnv)
+	client, err := NewClientFromConfig("")
+	c.Assert(err, check.IsNil)
+	c.Assert(client.Endpoint, check.Equals, "http://gandalf.dev")
+}
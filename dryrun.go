@@ -0,0 +1,35 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+// PlannedRequest is a mutating request recorded by a Client in dry-run
+// mode instead of being sent to the server.
+type PlannedRequest struct {
+	Method string
+	Path   string
+	Body   string
+}
+
+func (c *Client) addToPlan(method, path, body string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.plan = append(c.plan, PlannedRequest{Method: method, Path: path, Body: body})
+}
+
+// Plan returns the requests recorded in dry-run mode, in order.
+func (c *Client) Plan() []PlannedRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	plan := make([]PlannedRequest, len(c.plan))
+	copy(plan, c.plan)
+	return plan
+}
+
+// ResetPlan discards the requests recorded in dry-run mode.
+func (c *Client) ResetPlan() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.plan = nil
+}
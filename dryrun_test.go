@@ -0,0 +1,59 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"net/http/httptest"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestDryRun(c *check.C) {
+	h := testHandler{content: `{"name":"proj1","users":["user0"]}`}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL, DryRun: true}
+	r, err := client.NewRepository(ctx, "proj1", []string{"user1"}, true)
+	c.Assert(err, check.IsNil)
+	c.Assert(r, check.DeepEquals, repository{Name: "proj1", Users: []string{"user1"}, IsPublic: true})
+	u, err := client.NewUser(ctx, "user1", map[string]string{"k": "ssh-rsa AAAA"})
+	c.Assert(err, check.IsNil)
+	c.Assert(u, check.DeepEquals, user{Name: "user1", Keys: map[string]string{"k": "ssh-rsa AAAA"}})
+	err = client.UpdateKey(ctx, "user1", "k", "ssh-rsa BBBB")
+	c.Assert(err, check.IsNil)
+	err = client.RevokeAccess(ctx, []string{"proj1"}, []string{"user0"})
+	c.Assert(err, check.IsNil)
+	err = client.RemoveRepository(ctx, "proj0")
+	c.Assert(err, check.IsNil)
+	c.Assert(h.method, check.Equals, "")
+	c.Assert(client.Plan(), check.DeepEquals, []PlannedRequest{
+		{Method: "POST", Path: "/repository", Body: `{"name":"proj1","users":["user1"],"ispublic":true}`},
+		{Method: "POST", Path: "/user", Body: `{"name":"user1","keys":{"k":"ssh-rsa AAAA"}}`},
+		{Method: "PUT", Path: "/user/user1/key/k", Body: "ssh-rsa BBBB"},
+		{Method: "DELETE", Path: "/repository/revoke", Body: `{"repositories":["proj1"],"users":["user0"]}`},
+		{Method: "DELETE", Path: "/repository/proj0", Body: "null"},
+	})
+	client.ResetPlan()
+	c.Assert(client.Plan(), check.HasLen, 0)
+}
+
+func (s *S) TestDryRunReadsReachServer(c *check.C) {
+	h := testHandler{content: `{"name":"proj1","users":["user0"]}`}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL, DryRun: true}
+	r, err := client.GetRepository(ctx, "proj1")
+	c.Assert(err, check.IsNil)
+	c.Assert(r.Users, check.DeepEquals, []string{"user0"})
+	c.Assert(h.method, check.Equals, "GET")
+	c.Assert(client.Plan(), check.HasLen, 0)
+}
+
+func (s *S) TestDryRunMarshalingFailure(c *check.C) {
+	client := Client{Endpoint: "http://127.0.0.1:747399", DryRun: true}
+	err := client.post(ctx, unmarshable{}, "/users/something")
+	c.Assert(err, check.NotNil)
+	c.Assert(client.Plan(), check.HasLen, 0)
+}
@@ -0,0 +1,118 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+var errInvalidEndpoint = errors.New("invalid Gandalf endpoint")
+
+// endpoint is the parsed form of a Gandalf endpoint. Operation paths are
+// resolved against it, keeping the base path (e.g. when Gandalf is mounted
+// under a prefix by a reverse proxy) and the default query parameters.
+type endpoint struct {
+	prefix string
+	path   string
+	query  string
+	socket string
+}
+
+func parseEndpoint(raw string) (*endpoint, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, errInvalidEndpoint
+	}
+	e := endpoint{query: u.RawQuery}
+	switch u.Scheme {
+	case "http", "https":
+		if u.Host == "" {
+			return nil, errInvalidEndpoint
+		}
+		e.path = strings.TrimRight(u.EscapedPath(), "/")
+		u.Path, u.RawPath, u.RawQuery, u.ForceQuery, u.Fragment = "", "", "", false, ""
+		e.prefix = u.String()
+	case "unix":
+		e.socket = u.Host + u.Path
+		if e.socket == "" {
+			return nil, errInvalidEndpoint
+		}
+		e.prefix = "http://unix"
+	default:
+		return nil, errInvalidEndpoint
+	}
+	return &e, nil
+}
+
+// resolve returns the URL for the given operation path, which may carry
+// its own query string. Parameters set by the operation take precedence
+// over the default ones from the endpoint.
+func (e *endpoint) resolve(path string) string {
+	var query string
+	if i := strings.Index(path, "?"); i >= 0 {
+		path, query = path[:i], path[i+1:]
+	}
+	if e.query != "" {
+		query = joinQuery(e.defaultQuery(query), query)
+	}
+	u := e.prefix + e.path + path
+	if query != "" {
+		u += "?" + query
+	}
+	return u
+}
+
+// defaultQuery returns the endpoint query without the parameters
+// overridden by query.
+func (e *endpoint) defaultQuery(query string) string {
+	overridden, _ := url.ParseQuery(query)
+	var kept []string
+	for _, part := range strings.Split(e.query, "&") {
+		if part == "" {
+			continue
+		}
+		key := part
+		if i := strings.Index(key, "="); i >= 0 {
+			key = key[:i]
+		}
+		if k, err := url.QueryUnescape(key); err == nil {
+			key = k
+		}
+		if _, ok := overridden[key]; !ok {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, "&")
+}
+
+func joinQuery(a, b string) string {
+	if a == "" {
+		return b
+	}
+	if b == "" {
+		return a
+	}
+	return a + "&" + b
+}
+
+// parsedEndpoint returns the parsed form of raw, which is parsed only once.
+func (c *Client) parsedEndpoint(raw string) (*endpoint, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.endpoints[raw]; ok {
+		return e, nil
+	}
+	e, err := parseEndpoint(raw)
+	if err != nil {
+		return nil, err
+	}
+	if c.endpoints == nil {
+		c.endpoints = make(map[string]*endpoint)
+	}
+	c.endpoints[raw] = e
+	return e, nil
+}
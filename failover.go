@@ -0,0 +1,175 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// EndpointPolicy defines how a Client with several Endpoints picks the
+// replica that receives each request.
+type EndpointPolicy int
+
+const (
+	// PrimarySecondary sends every request to the first endpoint that is
+	// not marked as down.
+	PrimarySecondary EndpointPolicy = iota
+
+	// RoundRobinReads spreads read requests among the endpoints that are
+	// not marked as down. Writes go to the first available endpoint.
+	RoundRobinReads
+)
+
+const defaultHealthCheckInterval = 10 * time.Second
+
+// isIdempotent reports whether a request with the given method has the
+// same effect when sent more than once.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// canFailOver reports whether a request with the given method can be sent
+// again to another replica. Only reads are, as a mutation may have been
+// applied before its connection failed, and resending it would then fail
+// on the other replica, as when removing a user twice.
+func canFailOver(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// isUnavailable reports whether the status code indicates that the
+// replica, rather than the request, is the problem.
+func isUnavailable(code int) bool {
+	return code == http.StatusBadGateway || code == http.StatusServiceUnavailable || code == http.StatusGatewayTimeout
+}
+
+// candidates returns the endpoints that should be tried for a request, in
+// order. Endpoints marked as down come last, so they are only used when no
+// other endpoint is available.
+func (c *Client) candidates(method string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var up, down []string
+	for _, e := range c.Endpoints {
+		if c.down[e] {
+			down = append(down, e)
+		} else {
+			up = append(up, e)
+		}
+	}
+	if c.Policy == RoundRobinReads && method == http.MethodGet && len(up) > 1 {
+		n := int(c.reads % uint64(len(up)))
+		c.reads++
+		up = append(append([]string{}, up[n:]...), up[:n]...)
+	}
+	return append(up, down...)
+}
+
+func (c *Client) doFailoverRequest(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	candidates := c.candidates(method)
+	if !canFailOver(method) {
+		candidates = candidates[:1]
+	}
+	for i, endpoint := range candidates {
+		last := i == len(candidates)-1
+		response, err := c.doEndpointRequest(ctx, endpoint, method, path, body)
+		if err != nil {
+			var connErr *connectionError
+			if !errors.As(err, &connErr) || ctx.Err() != nil {
+				return nil, err
+			}
+			c.markDown(endpoint)
+			if last {
+				return nil, err
+			}
+			continue
+		}
+		if isUnavailable(response.StatusCode) {
+			c.markDown(endpoint)
+			if !last {
+				response.Body.Close()
+				continue
+			}
+		}
+		return response, nil
+	}
+	return nil, errInvalidEndpoint
+}
+
+// markDown stops sending traffic to endpoint until a health check
+// succeeds.
+func (c *Client) markDown(endpoint string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.down[endpoint] {
+		return
+	}
+	if c.down == nil {
+		c.down = make(map[string]bool)
+	}
+	if c.closed == nil {
+		c.closed = make(chan struct{})
+	}
+	c.down[endpoint] = true
+	go c.probe(endpoint, c.closed)
+}
+
+func (c *Client) probe(endpoint string, closed chan struct{}) {
+	interval := c.HealthCheckInterval
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+	probe := &Client{Endpoint: endpoint, Client: c.Client, TLS: c.TLS, Header: c.Header}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case <-ticker.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		_, err := probe.GetHealthCheck(ctx)
+		cancel()
+		if err == nil {
+			c.mu.Lock()
+			delete(c.down, endpoint)
+			c.mu.Unlock()
+			return
+		}
+	}
+}
+
+// Down returns the endpoints currently marked as down.
+func (c *Client) Down() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var down []string
+	for _, e := range c.Endpoints {
+		if c.down[e] {
+			down = append(down, e)
+		}
+	}
+	return down
+}
+
+// Close stops the background health checks of endpoints marked as down,
+// making all endpoints eligible for traffic again.
+func (c *Client) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed != nil {
+		close(c.closed)
+		c.closed = nil
+	}
+	c.down = nil
+}
@@ -0,0 +1,206 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+type replicaHandler struct {
+	mu       sync.Mutex
+	name     string
+	down     bool
+	requests []string
+}
+
+func (h *replicaHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.requests = append(h.requests, r.Method+" "+r.URL.Path+" "+string(body))
+	if h.down {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	w.Write([]byte(`{"name":"` + h.name + `"}`))
+}
+
+func (h *replicaHandler) setDown(down bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.down = down
+}
+
+func (h *replicaHandler) received() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.requests...)
+}
+
+func (s *S) TestFailoverPrimarySecondary(c *check.C) {
+	primary := httptest.NewServer(&replicaHandler{name: "primary"})
+	secondary := httptest.NewServer(&replicaHandler{name: "secondary"})
+	defer secondary.Close()
+	client := Client{Endpoints: []string{primary.URL, secondary.URL}}
+	defer client.Close()
+	r, err := client.GetRepository(ctx, "proj1")
+	c.Assert(err, check.IsNil)
+	c.Assert(r.Name, check.Equals, "primary")
+	primary.Close()
+	r, err = client.GetRepository(ctx, "proj1")
+	c.Assert(err, check.IsNil)
+	c.Assert(r.Name, check.Equals, "secondary")
+	c.Assert(client.Down(), check.DeepEquals, []string{primary.URL})
+}
+
+func (s *S) TestFailoverDoesNotRetryMutations(c *check.C) {
+	h1 := replicaHandler{name: "dc1", down: true}
+	h2 := replicaHandler{name: "dc2"}
+	dc1 := httptest.NewServer(&h1)
+	defer dc1.Close()
+	dc2 := httptest.NewServer(&h2)
+	defer dc2.Close()
+	client := Client{Endpoints: []string{dc1.URL, dc2.URL}}
+	defer client.Close()
+	err := client.RevokeAccess(ctx, []string{"proj1"}, []string{"user1"})
+	c.Assert(err, check.ErrorMatches, "unavailable\n")
+	body := `DELETE /repository/revoke {"repositories":["proj1"],"users":["user1"]}`
+	c.Assert(h1.received(), check.DeepEquals, []string{body})
+	c.Assert(h2.received(), check.HasLen, 0)
+	err = client.RevokeAccess(ctx, []string{"proj1"}, []string{"user1"})
+	c.Assert(err, check.IsNil)
+	c.Assert(h2.received(), check.DeepEquals, []string{body})
+}
+
+func (s *S) TestFailoverDoesNotRetryMutationsOnConnectionErrors(c *check.C) {
+	var (
+		mu       sync.Mutex
+		requests int
+	)
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	defer primary.Close()
+	h := replicaHandler{name: "secondary"}
+	secondary := httptest.NewServer(&h)
+	defer secondary.Close()
+	for _, call := range []func(client *Client) error{
+		func(client *Client) error { return client.RemoveUser(ctx, "user1") },
+		func(client *Client) error { return client.UpdateKey(ctx, "user1", "key1", testKey) },
+	} {
+		client := Client{Endpoints: []string{primary.URL, secondary.URL}}
+		err := call(&client)
+		client.Close()
+		c.Assert(err, check.ErrorMatches, "Failed to connect to Gandalf server .*")
+	}
+	c.Assert(count(), check.Equals, 2)
+	c.Assert(h.received(), check.HasLen, 0)
+	client := Client{Endpoints: []string{primary.URL, secondary.URL}}
+	defer client.Close()
+	_, err := client.GetRepository(ctx, "proj1")
+	c.Assert(err, check.IsNil)
+	c.Assert(count(), check.Equals, 3)
+	c.Assert(h.received(), check.HasLen, 1)
+}
+
+func (s *S) TestFailoverDoesNotRetryNonIdempotentRequests(c *check.C) {
+	h1 := replicaHandler{name: "dc1", down: true}
+	h2 := replicaHandler{name: "dc2"}
+	dc1 := httptest.NewServer(&h1)
+	defer dc1.Close()
+	dc2 := httptest.NewServer(&h2)
+	defer dc2.Close()
+	client := Client{Endpoints: []string{dc1.URL, dc2.URL}}
+	defer client.Close()
+	_, err := client.NewRepository(ctx, "proj1", nil, false)
+	c.Assert(err, check.ErrorMatches, "unavailable\n")
+	c.Assert(h1.received(), check.HasLen, 1)
+	c.Assert(h2.received(), check.HasLen, 0)
+	_, err = client.NewRepository(ctx, "proj1", nil, false)
+	c.Assert(err, check.IsNil)
+	c.Assert(h1.received(), check.HasLen, 1)
+	c.Assert(h2.received(), check.HasLen, 1)
+}
+
+func (s *S) TestFailoverAllEndpointsDown(c *check.C) {
+	client := Client{Endpoints: []string{"http://127.0.0.1:747399", "http://127.0.0.1:747398"}}
+	defer client.Close()
+	_, err := client.doRequest(ctx, "GET", "/healthcheck", nil)
+	c.Assert(err, check.ErrorMatches, `Failed to connect to Gandalf server \(http://127.0.0.1:747398\) - .*`)
+	c.Assert(client.Down(), check.HasLen, 2)
+}
+
+func (s *S) TestFailoverRoundRobinReads(c *check.C) {
+	h1 := replicaHandler{name: "dc1"}
+	h2 := replicaHandler{name: "dc2"}
+	dc1 := httptest.NewServer(&h1)
+	defer dc1.Close()
+	dc2 := httptest.NewServer(&h2)
+	defer dc2.Close()
+	client := Client{Endpoints: []string{dc1.URL, dc2.URL}, Policy: RoundRobinReads}
+	defer client.Close()
+	var names []string
+	for i := 0; i < 4; i++ {
+		r, err := client.GetRepository(ctx, "proj1")
+		c.Assert(err, check.IsNil)
+		names = append(names, r.Name)
+	}
+	c.Assert(names, check.DeepEquals, []string{"dc1", "dc2", "dc1", "dc2"})
+	for i := 0; i < 2; i++ {
+		err := client.RemoveUser(ctx, "user1")
+		c.Assert(err, check.IsNil)
+	}
+	c.Assert(h1.received(), check.HasLen, 4)
+	c.Assert(h2.received(), check.HasLen, 2)
+}
+
+func (s *S) TestFailoverHealthCheckRestoresEndpoint(c *check.C) {
+	h1 := replicaHandler{name: "dc1", down: true}
+	h2 := replicaHandler{name: "dc2"}
+	dc1 := httptest.NewServer(&h1)
+	defer dc1.Close()
+	dc2 := httptest.NewServer(&h2)
+	defer dc2.Close()
+	client := Client{Endpoints: []string{dc1.URL, dc2.URL}, HealthCheckInterval: 10 * time.Millisecond}
+	defer client.Close()
+	r, err := client.GetRepository(ctx, "proj1")
+	c.Assert(err, check.IsNil)
+	c.Assert(r.Name, check.Equals, "dc2")
+	c.Assert(client.Down(), check.DeepEquals, []string{dc1.URL})
+	time.Sleep(50 * time.Millisecond)
+	r, err = client.GetRepository(ctx, "proj1")
+	c.Assert(err, check.IsNil)
+	c.Assert(r.Name, check.Equals, "dc2")
+	h1.setDown(false)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(client.Down()) > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Assert(client.Down(), check.HasLen, 0)
+	r, err = client.GetRepository(ctx, "proj1")
+	c.Assert(err, check.IsNil)
+	c.Assert(r.Name, check.Equals, "dc1")
+	received := h1.received()
+	c.Assert(received[0], check.Equals, "GET /repository/proj1 ")
+	c.Assert(received[len(received)-1], check.Equals, "GET /repository/proj1 ")
+	for _, req := range received[1 : len(received)-1] {
+		c.Assert(req, check.Equals, "GET /healthcheck ")
+	}
+}
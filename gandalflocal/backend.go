@@ -0,0 +1,587 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package gandalflocal implements the Gandalf client API against bare
+// repositories on local disk, for development without a Gandalf server.
+//
+// Git operations shell out to the git binary, while users, keys and access
+// grants are kept in a JSON file in the root directory.
+package gandalflocal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	gandalf "github.com/tsuru/go-gandalfclient"
+)
+
+// Backend implements gandalf.API on top of a directory of bare
+// repositories. Failures are reported as *gandalf.HTTPError with the status
+// code Gandalf would use, so callers handle both implementations alike.
+// Call options only affect HTTP requests, so they are ignored.
+type Backend struct {
+	root    string
+	gitPath string
+	mu      sync.Mutex
+}
+
+var _ gandalf.API = &Backend{}
+
+// New returns a backend that keeps its repositories and data in root,
+// creating the directory if needed.
+func New(root string) (*Backend, error) {
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		return nil, err
+	}
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+	return &Backend{root: root, gitPath: gitPath}, nil
+}
+
+func httpError(code int, format string, args ...interface{}) error {
+	return &gandalf.HTTPError{Code: code, Reason: fmt.Sprintf(format, args...)}
+}
+
+func errRepositoryNotFound() error {
+	return httpError(http.StatusNotFound, "repository not found")
+}
+
+func errUserNotFound() error {
+	return httpError(http.StatusNotFound, "user not found")
+}
+
+// repoPath returns the path of the bare repository with the given name,
+// which may include a namespace (e.g. "team/app").
+func (b *Backend) repoPath(name string) (string, error) {
+	clean := filepath.ToSlash(filepath.Clean(name))
+	if name == "" || clean != name || strings.HasPrefix(name, "/") || strings.HasPrefix(name, "..") || strings.Contains(name, "/../") {
+		return "", httpError(http.StatusBadRequest, "invalid repository name: %q", name)
+	}
+	return filepath.Join(b.root, filepath.FromSlash(name)+".git"), nil
+}
+
+// command returns a git command to be run in the repository.
+func (b *Backend) command(ctx context.Context, repo string, args ...string) (*exec.Cmd, error) {
+	path, err := b.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, errRepositoryNotFound()
+	}
+	cmd := exec.CommandContext(ctx, b.gitPath, args...)
+	cmd.Dir = path
+	return cmd, nil
+}
+
+// checkRefs rejects refs that git would read as options, such as
+// "--output=file", as they are passed to git as arguments.
+func checkRefs(refs ...string) error {
+	for _, ref := range refs {
+		if strings.HasPrefix(ref, "-") {
+			return httpError(http.StatusBadRequest, "invalid ref: %q", ref)
+		}
+	}
+	return nil
+}
+
+func (b *Backend) git(ctx context.Context, repo string, args ...string) ([]byte, error) {
+	cmd, err := b.command(ctx, repo, args...)
+	if err != nil {
+		return nil, err
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		reason := strings.TrimSpace(stderr.String())
+		if reason == "" {
+			reason = err.Error()
+		}
+		return nil, httpError(http.StatusBadRequest, "%s", reason)
+	}
+	return out, nil
+}
+
+func (b *Backend) NewRepository(ctx context.Context, name string, users []string, isPublic bool, opts ...gandalf.CallOption) (gandalf.Repository, error) {
+	path, err := b.repoPath(name)
+	if err != nil {
+		return gandalf.Repository{}, err
+	}
+	err = b.update(func(s *store) error {
+		if _, ok := s.Repositories[name]; ok {
+			return httpError(http.StatusConflict, "repository already exists")
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		cmd := exec.CommandContext(ctx, b.gitPath, "init", "--bare", "--quiet", path)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("could not create repository: %s", strings.TrimSpace(string(out)))
+		}
+		s.Repositories[name] = &repositoryMeta{Users: users, IsPublic: isPublic}
+		return nil
+	})
+	if err != nil {
+		return gandalf.Repository{}, err
+	}
+	return gandalf.Repository{Name: name, Users: users, IsPublic: isPublic}, nil
+}
+
+func (b *Backend) GetRepository(ctx context.Context, name string, opts ...gandalf.CallOption) (gandalf.Repository, error) {
+	path, err := b.repoPath(name)
+	if err != nil {
+		return gandalf.Repository{}, err
+	}
+	var r gandalf.Repository
+	err = b.view(func(s *store) error {
+		meta, ok := s.Repositories[name]
+		if !ok {
+			return errRepositoryNotFound()
+		}
+		r = gandalf.Repository{
+			Name:     name,
+			Users:    meta.Users,
+			IsPublic: meta.IsPublic,
+			GitURL:   "file://" + filepath.ToSlash(path),
+		}
+		return nil
+	})
+	return r, err
+}
+
+func (b *Backend) RemoveRepository(ctx context.Context, name string, opts ...gandalf.CallOption) error {
+	path, err := b.repoPath(name)
+	if err != nil {
+		return err
+	}
+	return b.update(func(s *store) error {
+		if _, ok := s.Repositories[name]; !ok {
+			return errRepositoryNotFound()
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+		delete(s.Repositories, name)
+		return nil
+	})
+}
+
+func (b *Backend) NewUser(ctx context.Context, name string, keys map[string]string, opts ...gandalf.CallOption) (gandalf.User, error) {
+	err := b.update(func(s *store) error {
+		if _, ok := s.Users[name]; ok {
+			return httpError(http.StatusConflict, "user already exists")
+		}
+		stored := make(map[string]string, len(keys))
+		for k, v := range keys {
+			stored[k] = v
+		}
+		s.Users[name] = stored
+		return nil
+	})
+	if err != nil {
+		return gandalf.User{}, err
+	}
+	return gandalf.User{Name: name, Keys: keys}, nil
+}
+
+func (b *Backend) RemoveUser(ctx context.Context, name string, opts ...gandalf.CallOption) error {
+	return b.update(func(s *store) error {
+		if _, ok := s.Users[name]; !ok {
+			return errUserNotFound()
+		}
+		delete(s.Users, name)
+		for _, meta := range s.Repositories {
+			meta.Users = without(meta.Users, name)
+		}
+		return nil
+	})
+}
+
+func (b *Backend) GrantAccess(ctx context.Context, rNames, uNames []string, opts ...gandalf.CallOption) error {
+	return b.update(func(s *store) error {
+		for _, r := range rNames {
+			meta, ok := s.Repositories[r]
+			if !ok {
+				return errRepositoryNotFound()
+			}
+			for _, u := range uNames {
+				if !contains(meta.Users, u) {
+					meta.Users = append(meta.Users, u)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (b *Backend) RevokeAccess(ctx context.Context, rNames, uNames []string, opts ...gandalf.CallOption) error {
+	return b.update(func(s *store) error {
+		for _, r := range rNames {
+			meta, ok := s.Repositories[r]
+			if !ok {
+				return errRepositoryNotFound()
+			}
+			for _, u := range uNames {
+				meta.Users = without(meta.Users, u)
+			}
+		}
+		return nil
+	})
+}
+
+func (b *Backend) AddKey(ctx context.Context, uName string, key map[string]string, opts ...gandalf.CallOption) error {
+	return b.update(func(s *store) error {
+		keys, ok := s.Users[uName]
+		if !ok {
+			return errUserNotFound()
+		}
+		for name := range key {
+			if _, ok := keys[name]; ok {
+				return httpError(http.StatusConflict, "key %q already exists", name)
+			}
+		}
+		for name, body := range key {
+			keys[name] = body
+		}
+		return nil
+	})
+}
+
+func (b *Backend) UpdateKey(ctx context.Context, uName, kName, kBody string, opts ...gandalf.CallOption) error {
+	return b.update(func(s *store) error {
+		keys, ok := s.Users[uName]
+		if !ok {
+			return errUserNotFound()
+		}
+		if _, ok := keys[kName]; !ok {
+			return httpError(http.StatusNotFound, "key not found")
+		}
+		keys[kName] = kBody
+		return nil
+	})
+}
+
+func (b *Backend) RemoveKey(ctx context.Context, uName, kName string, opts ...gandalf.CallOption) error {
+	return b.update(func(s *store) error {
+		keys, ok := s.Users[uName]
+		if !ok {
+			return errUserNotFound()
+		}
+		if _, ok := keys[kName]; !ok {
+			return httpError(http.StatusNotFound, "key not found")
+		}
+		delete(keys, kName)
+		return nil
+	})
+}
+
+func (b *Backend) ListKeys(ctx context.Context, uName string, opts ...gandalf.CallOption) (map[string]string, error) {
+	keys := map[string]string{}
+	err := b.view(func(s *store) error {
+		stored, ok := s.Users[uName]
+		if !ok {
+			return errUserNotFound()
+		}
+		for k, v := range stored {
+			keys[k] = v
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (b *Backend) GetDiff(ctx context.Context, repo, previousCommit, lastCommit string, opts ...gandalf.CallOption) (string, error) {
+	if err := checkRefs(previousCommit, lastCommit); err != nil {
+		return "", err
+	}
+	out, err := b.git(ctx, repo, "diff", previousCommit, lastCommit)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// logFormat separates the fields of each commit with NUL and the commits
+// with the record separator character.
+const logFormat = "--format=%H%x00%an%x00%ae%x00%ad%x00%cn%x00%ce%x00%cd%x00%s%x00%P%x00%B%x1e"
+
+type logEntry struct {
+	gandalf.Commit
+	message string
+}
+
+func (b *Backend) log(ctx context.Context, repo string, limit int, revs ...string) ([]logEntry, error) {
+	args := []string{"log", "--date=raw", logFormat}
+	if limit > 0 {
+		args = append(args, "-n", strconv.Itoa(limit))
+	}
+	args = append(args, revs...)
+	out, err := b.git(ctx, repo, args...)
+	if err != nil {
+		return nil, err
+	}
+	var entries []logEntry
+	for _, record := range strings.Split(string(out), "\x1e") {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+		fields := strings.SplitN(record, "\x00", 10)
+		if len(fields) != 10 {
+			return nil, fmt.Errorf("unexpected git log output: %q", record)
+		}
+		authorDate, err := gandalf.ParseGitTime(fields[3])
+		if err != nil {
+			return nil, err
+		}
+		committerDate, err := gandalf.ParseGitTime(fields[6])
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, logEntry{
+			Commit: gandalf.Commit{
+				Ref:       fields[0],
+				Author:    gandalf.Author{Name: fields[1], Email: fields[2], Date: authorDate},
+				Committer: gandalf.Author{Name: fields[4], Email: fields[5], Date: committerDate},
+				Subject:   fields[7],
+				CreatedAt: authorDate,
+				Parent:    strings.Fields(fields[8]),
+			},
+			message: strings.TrimRight(fields[9], "\n"),
+		})
+	}
+	return entries, nil
+}
+
+func (b *Backend) GetLog(ctx context.Context, repo, ref, path string, total int, opts ...gandalf.CallOption) (gandalf.Log, error) {
+	if err := checkRefs(ref); err != nil {
+		return gandalf.Log{}, err
+	}
+	limit := 0
+	if total > 0 {
+		limit = total + 1
+	}
+	revs := []string{ref}
+	if path != "" {
+		revs = append(revs, "--", path)
+	}
+	entries, err := b.log(ctx, repo, limit, revs...)
+	if err != nil {
+		return gandalf.Log{}, err
+	}
+	var log gandalf.Log
+	for i, entry := range entries {
+		if total > 0 && i == total {
+			log.Next = entry.Ref
+			break
+		}
+		log.Commits = append(log.Commits, entry.Commit)
+	}
+	return log, nil
+}
+
+func (b *Backend) GetCommit(ctx context.Context, repo, ref string, opts ...gandalf.CallOption) (gandalf.CommitDetail, error) {
+	if err := checkRefs(ref); err != nil {
+		return gandalf.CommitDetail{}, err
+	}
+	entries, err := b.log(ctx, repo, 1, ref)
+	if err != nil {
+		return gandalf.CommitDetail{}, err
+	}
+	if len(entries) == 0 {
+		return gandalf.CommitDetail{}, fmt.Errorf("commit %q not found in repository %q", ref, repo)
+	}
+	entry := entries[0]
+	previous := gandalf.EmptyTree
+	if len(entry.Parent) > 0 {
+		previous = entry.Parent[0]
+	}
+	diff, err := b.GetDiff(ctx, repo, previous, entry.Ref)
+	if err != nil {
+		return gandalf.CommitDetail{}, err
+	}
+	return gandalf.CommitDetail{
+		Commit:   entry.Commit,
+		Message:  entry.message,
+		Trailers: gandalf.ParseTrailers(entry.message),
+		Files:    gandalf.ParseDiffStats(diff),
+	}, nil
+}
+
+func (b *Backend) Compare(ctx context.Context, repo, base, head string, opts ...gandalf.CallOption) (gandalf.Comparison, error) {
+	if err := checkRefs(base, head); err != nil {
+		return gandalf.Comparison{}, err
+	}
+	cmp := gandalf.Comparison{Base: base, Head: head}
+	cmd, err := b.command(ctx, repo, "merge-base", base, head)
+	if err != nil {
+		return gandalf.Comparison{}, err
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		// merge-base exits with 1, without any message, for unrelated
+		// histories.
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 || len(exitErr.Stderr) > 0 {
+			reason := err.Error()
+			if exitErr != nil && len(exitErr.Stderr) > 0 {
+				reason = strings.TrimSpace(string(exitErr.Stderr))
+			}
+			return gandalf.Comparison{}, httpError(http.StatusBadRequest, "%s", reason)
+		}
+	}
+	cmp.MergeBase = strings.TrimSpace(string(out))
+	for _, side := range []struct {
+		revs    []string
+		commits *[]gandalf.Commit
+	}{
+		{[]string{head, "--not", base}, &cmp.AheadCommits},
+		{[]string{base, "--not", head}, &cmp.BehindCommits},
+	} {
+		entries, err := b.log(ctx, repo, 0, side.revs...)
+		if err != nil {
+			return gandalf.Comparison{}, err
+		}
+		for _, entry := range entries {
+			*side.commits = append(*side.commits, entry.Commit)
+		}
+	}
+	cmp.Ahead = len(cmp.AheadCommits)
+	cmp.Behind = len(cmp.BehindCommits)
+	return cmp, nil
+}
+
+func (b *Backend) GetBranches(ctx context.Context, repo string, opts ...gandalf.CallOption) ([]gandalf.Branch, error) {
+	format := "--format=%(refname:short)%00%(objectname)%00%(subject)%00%(authorname)%00%(authoremail)%00%(authordate:raw)%00%(committername)%00%(committeremail)%00%(committerdate:raw)"
+	out, err := b.git(ctx, repo, "for-each-ref", format, "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+	var branches []gandalf.Branch
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 9 {
+			return nil, fmt.Errorf("unexpected git for-each-ref output: %q", line)
+		}
+		authorDate, err := gandalf.ParseGitTime(fields[5])
+		if err != nil {
+			return nil, err
+		}
+		committerDate, err := gandalf.ParseGitTime(fields[8])
+		if err != nil {
+			return nil, err
+		}
+		branches = append(branches, gandalf.Branch{
+			Name:      fields[0],
+			Ref:       fields[1],
+			Subject:   fields[2],
+			CreatedAt: authorDate,
+			Author:    gandalf.Author{Name: fields[3], Email: strings.Trim(fields[4], "<>"), Date: authorDate},
+			Committer: gandalf.Author{Name: fields[6], Email: strings.Trim(fields[7], "<>"), Date: committerDate},
+		})
+	}
+	return branches, nil
+}
+
+func (b *Backend) GetTree(ctx context.Context, repo, ref, path string, opts ...gandalf.CallOption) ([]gandalf.TreeEntry, error) {
+	if err := checkRefs(ref); err != nil {
+		return nil, err
+	}
+	args := []string{"ls-tree", "-r", "-z", ref}
+	if path != "" {
+		args = append(args, "--", path)
+	}
+	out, err := b.git(ctx, repo, args...)
+	if err != nil {
+		return nil, err
+	}
+	var tree []gandalf.TreeEntry
+	for _, line := range strings.Split(string(out), "\x00") {
+		if line == "" {
+			continue
+		}
+		tab := strings.Index(line, "\t")
+		if tab < 0 {
+			return nil, fmt.Errorf("unexpected git ls-tree output: %q", line)
+		}
+		fields := strings.Fields(line[:tab])
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("unexpected git ls-tree output: %q", line)
+		}
+		tree = append(tree, gandalf.TreeEntry{
+			Permission: fields[0],
+			FileType:   fields[1],
+			Hash:       fields[2],
+			Path:       line[tab+1:],
+			RawPath:    line[tab+1:],
+		})
+	}
+	return tree, nil
+}
+
+func (b *Backend) GetArchive(ctx context.Context, repo, ref string, format gandalf.ArchiveFormat, opts ...gandalf.CallOption) (io.ReadCloser, error) {
+	switch format {
+	case gandalf.Zip, gandalf.Tar, gandalf.TarGz:
+	default:
+		return nil, httpError(http.StatusBadRequest, "invalid archive format: %q", format)
+	}
+	if err := checkRefs(ref); err != nil {
+		return nil, err
+	}
+	out, err := b.git(ctx, repo, "archive", "--format="+string(format), ref)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(bytes.NewReader(out)), nil
+}
+
+func (b *Backend) GetHealthCheck(ctx context.Context, opts ...gandalf.CallOption) ([]byte, error) {
+	if _, err := os.Stat(b.root); err != nil {
+		return []byte{}, &gandalf.HTTPError{Code: http.StatusInternalServerError, Reason: err.Error()}
+	}
+	return []byte("WORKING"), nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func without(list []string, s string) []string {
+	var result []string
+	for _, item := range list {
+		if item != s {
+			result = append(result, item)
+		}
+	}
+	return result
+}
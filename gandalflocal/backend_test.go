@@ -0,0 +1,240 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalflocal
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gandalf "github.com/tsuru/go-gandalfclient"
+	"gopkg.in/check.v1"
+)
+
+// pushCommits creates a repository in the backend with two commits on
+// master and one on a feature branch, returning the work tree.
+func (s *S) pushCommits(c *check.C, repo string) string {
+	_, err := s.backend.NewRepository(ctx, repo, nil, false)
+	c.Assert(err, check.IsNil)
+	r, err := s.backend.GetRepository(ctx, repo)
+	c.Assert(err, check.IsNil)
+	work := c.MkDir()
+	run(c, work, "init", "--quiet")
+	run(c, work, "checkout", "--quiet", "-b", "master")
+	c.Assert(ioutil.WriteFile(filepath.Join(work, "README.md"), []byte("Gandalf\n"), 0644), check.IsNil)
+	run(c, work, "add", ".")
+	run(c, work, "commit", "--quiet", "-m", "Initial commit")
+	c.Assert(ioutil.WriteFile(filepath.Join(work, "README.md"), []byte("Gandalf\nclient\n"), 0644), check.IsNil)
+	c.Assert(os.Mkdir(filepath.Join(work, "docs"), 0755), check.IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(work, "docs", "api.md"), []byte("API\n"), 0644), check.IsNil)
+	run(c, work, "add", ".")
+	run(c, work, "commit", "--quiet", "-m", "Document the client\n\nExplain the API.\n\nSigned-off-by: Joao Jose <joaojose@eu.com>")
+	run(c, work, "checkout", "--quiet", "-b", "feature", "HEAD~1")
+	c.Assert(ioutil.WriteFile(filepath.Join(work, "feature.txt"), []byte("feature\n"), 0644), check.IsNil)
+	run(c, work, "add", ".")
+	run(c, work, "commit", "--quiet", "-m", "Add feature")
+	run(c, work, "push", "--quiet", strings.TrimPrefix(r.GitURL, "file://"), "master", "feature")
+	return work
+}
+
+func (s *S) TestRepositories(c *check.C) {
+	r, err := s.backend.NewRepository(ctx, "team/app", []string{"user1"}, true)
+	c.Assert(err, check.IsNil)
+	c.Assert(r, check.DeepEquals, gandalf.Repository{Name: "team/app", Users: []string{"user1"}, IsPublic: true})
+	_, err = s.backend.NewRepository(ctx, "team/app", nil, false)
+	c.Assert(err, check.FitsTypeOf, &gandalf.HTTPError{})
+	c.Assert(err.(*gandalf.HTTPError).Code, check.Equals, http.StatusConflict)
+	r, err = s.backend.GetRepository(ctx, "team/app")
+	c.Assert(err, check.IsNil)
+	c.Assert(r.Users, check.DeepEquals, []string{"user1"})
+	c.Assert(r.GitURL, check.Equals, "file://"+filepath.Join(s.backend.root, "team", "app.git"))
+	_, err = os.Stat(filepath.Join(s.backend.root, "team", "app.git", "HEAD"))
+	c.Assert(err, check.IsNil)
+	err = s.backend.RemoveRepository(ctx, "team/app")
+	c.Assert(err, check.IsNil)
+	_, err = s.backend.GetRepository(ctx, "team/app")
+	c.Assert(err, check.ErrorMatches, "repository not found")
+	_, err = os.Stat(filepath.Join(s.backend.root, "team", "app.git"))
+	c.Assert(os.IsNotExist(err), check.Equals, true)
+}
+
+func (s *S) TestInvalidRepositoryName(c *check.C) {
+	for _, name := range []string{"", "../escape", "/abs", "a/../../b", "a//b"} {
+		_, err := s.backend.NewRepository(ctx, name, nil, false)
+		c.Check(err, check.ErrorMatches, "invalid repository name: .*", check.Commentf("name %q", name))
+	}
+}
+
+func (s *S) TestUsersKeysAndAccess(c *check.C) {
+	_, err := s.backend.NewRepository(ctx, "app", nil, false)
+	c.Assert(err, check.IsNil)
+	_, err = s.backend.NewUser(ctx, "user1", map[string]string{"k1": "ssh-rsa AAAA"})
+	c.Assert(err, check.IsNil)
+	c.Assert(s.backend.AddKey(ctx, "user1", map[string]string{"k2": "ssh-rsa BBBB"}), check.IsNil)
+	c.Assert(s.backend.AddKey(ctx, "user1", map[string]string{"k2": "ssh-rsa CCCC"}), check.ErrorMatches, `key "k2" already exists`)
+	c.Assert(s.backend.UpdateKey(ctx, "user1", "k2", "ssh-rsa CCCC"), check.IsNil)
+	c.Assert(s.backend.RemoveKey(ctx, "user1", "k1"), check.IsNil)
+	c.Assert(s.backend.RemoveKey(ctx, "user1", "k1"), check.ErrorMatches, "key not found")
+	keys, err := s.backend.ListKeys(ctx, "user1")
+	c.Assert(err, check.IsNil)
+	c.Assert(keys, check.DeepEquals, map[string]string{"k2": "ssh-rsa CCCC"})
+	c.Assert(s.backend.GrantAccess(ctx, []string{"app"}, []string{"user1", "user2"}), check.IsNil)
+	c.Assert(s.backend.GrantAccess(ctx, []string{"app"}, []string{"user1"}), check.IsNil)
+	r, err := s.backend.GetRepository(ctx, "app")
+	c.Assert(err, check.IsNil)
+	c.Assert(r.Users, check.DeepEquals, []string{"user1", "user2"})
+	c.Assert(s.backend.RevokeAccess(ctx, []string{"app"}, []string{"user2"}), check.IsNil)
+	c.Assert(s.backend.RemoveUser(ctx, "user1"), check.IsNil)
+	r, err = s.backend.GetRepository(ctx, "app")
+	c.Assert(err, check.IsNil)
+	c.Assert(r.Users, check.HasLen, 0)
+	_, err = s.backend.ListKeys(ctx, "user1")
+	c.Assert(err, check.ErrorMatches, "user not found")
+	c.Assert(s.backend.GrantAccess(ctx, []string{"missing"}, []string{"user1"}), check.ErrorMatches, "repository not found")
+}
+
+func (s *S) TestStorePersists(c *check.C) {
+	_, err := s.backend.NewUser(ctx, "user1", map[string]string{"k1": "ssh-rsa AAAA"})
+	c.Assert(err, check.IsNil)
+	other, err := New(s.backend.root)
+	c.Assert(err, check.IsNil)
+	keys, err := other.ListKeys(ctx, "user1")
+	c.Assert(err, check.IsNil)
+	c.Assert(keys, check.DeepEquals, map[string]string{"k1": "ssh-rsa AAAA"})
+}
+
+func (s *S) TestGetLog(c *check.C) {
+	s.pushCommits(c, "app")
+	log, err := s.backend.GetLog(ctx, "app", "master", "", 1)
+	c.Assert(err, check.IsNil)
+	c.Assert(log.Commits, check.HasLen, 1)
+	commit := log.Commits[0]
+	c.Assert(commit.Subject, check.Equals, "Document the client")
+	c.Assert(commit.Author.Name, check.Equals, "Joao Jose")
+	c.Assert(commit.Author.Email, check.Equals, "joaojose@eu.com")
+	c.Assert(commit.CreatedAt.String(), check.Equals, "Tue Dec  1 18:57:08 2015 -0200")
+	c.Assert(commit.Parent, check.HasLen, 1)
+	c.Assert(log.Next, check.Equals, commit.Parent[0])
+	log, err = s.backend.GetLog(ctx, "app", log.Next, "", 10)
+	c.Assert(err, check.IsNil)
+	c.Assert(log.Commits, check.HasLen, 1)
+	c.Assert(log.Commits[0].Subject, check.Equals, "Initial commit")
+	c.Assert(log.Next, check.Equals, "")
+	log, err = s.backend.GetLog(ctx, "app", "master", "docs", 0)
+	c.Assert(err, check.IsNil)
+	c.Assert(log.Commits, check.HasLen, 1)
+	_, err = s.backend.GetLog(ctx, "app", "missing", "", 1)
+	c.Assert(err, check.ErrorMatches, "(?s).*unknown revision.*")
+	_, err = s.backend.GetLog(ctx, "other", "master", "", 1)
+	c.Assert(err, check.ErrorMatches, "repository not found")
+}
+
+func (s *S) TestGetDiffAndCommit(c *check.C) {
+	s.pushCommits(c, "app")
+	commit, err := s.backend.GetCommit(ctx, "app", "master")
+	c.Assert(err, check.IsNil)
+	c.Assert(commit.Message, check.Equals, "Document the client\n\nExplain the API.\n\nSigned-off-by: Joao Jose <joaojose@eu.com>")
+	c.Assert(commit.Trailers, check.DeepEquals, map[string][]string{"Signed-off-by": {"Joao Jose <joaojose@eu.com>"}})
+	c.Assert(commit.Files, check.DeepEquals, []gandalf.FileStat{
+		{Path: "README.md", Added: 1},
+		{Path: "docs/api.md", Added: 1},
+	})
+	diff, err := s.backend.GetDiff(ctx, "app", commit.Parent[0], commit.Ref)
+	c.Assert(err, check.IsNil)
+	c.Assert(diff, check.Matches, "(?s)diff --git a/README.md b/README.md.*\\+client.*")
+	root, err := s.backend.GetCommit(ctx, "app", commit.Parent[0])
+	c.Assert(err, check.IsNil)
+	c.Assert(root.Files, check.DeepEquals, []gandalf.FileStat{{Path: "README.md", Added: 1}})
+}
+
+func (s *S) TestCompare(c *check.C) {
+	s.pushCommits(c, "app")
+	cmp, err := s.backend.Compare(ctx, "app", "master", "feature")
+	c.Assert(err, check.IsNil)
+	c.Assert(cmp.Ahead, check.Equals, 1)
+	c.Assert(cmp.Behind, check.Equals, 1)
+	c.Assert(cmp.AheadCommits[0].Subject, check.Equals, "Add feature")
+	c.Assert(cmp.BehindCommits[0].Subject, check.Equals, "Document the client")
+	log, err := s.backend.GetLog(ctx, "app", "feature", "", 2)
+	c.Assert(err, check.IsNil)
+	c.Assert(cmp.MergeBase, check.Equals, log.Commits[1].Ref)
+	_, err = s.backend.Compare(ctx, "app", "master", "missing")
+	c.Assert(err, check.NotNil)
+}
+
+func (s *S) TestGetTreeAndArchive(c *check.C) {
+	s.pushCommits(c, "app")
+	tree, err := s.backend.GetTree(ctx, "app", "master", "")
+	c.Assert(err, check.IsNil)
+	c.Assert(tree, check.HasLen, 2)
+	c.Assert(tree[0].Path, check.Equals, "README.md")
+	c.Assert(tree[0].FileType, check.Equals, "blob")
+	c.Assert(tree[0].Permission, check.Equals, "100644")
+	c.Assert(tree[1].Path, check.Equals, "docs/api.md")
+	tree, err = s.backend.GetTree(ctx, "app", "master", "docs")
+	c.Assert(err, check.IsNil)
+	c.Assert(tree, check.HasLen, 1)
+	archive, err := s.backend.GetArchive(ctx, "app", "master", gandalf.Zip)
+	c.Assert(err, check.IsNil)
+	data, err := ioutil.ReadAll(archive)
+	c.Assert(err, check.IsNil)
+	c.Assert(archive.Close(), check.IsNil)
+	z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	c.Assert(err, check.IsNil)
+	var names []string
+	for _, f := range z.File {
+		names = append(names, f.Name)
+	}
+	c.Assert(names, check.DeepEquals, []string{"README.md", "docs/", "docs/api.md"})
+	_, err = s.backend.GetArchive(ctx, "app", "master", "rar")
+	c.Assert(err, check.ErrorMatches, `invalid archive format: "rar"`)
+}
+
+func (s *S) TestRefsAreNotOptions(c *check.C) {
+	s.pushCommits(c, "app")
+	output := filepath.Join(c.MkDir(), "output")
+	_, err := s.backend.GetDiff(ctx, "app", "--output="+output, "master")
+	c.Assert(err, check.ErrorMatches, `invalid ref: "--output=.*"`)
+	c.Assert(err.(*gandalf.HTTPError).Code, check.Equals, http.StatusBadRequest)
+	_, err = os.Stat(output)
+	c.Assert(os.IsNotExist(err), check.Equals, true)
+	_, err = s.backend.GetLog(ctx, "app", "--output="+output, "", 1)
+	c.Assert(err, check.ErrorMatches, `invalid ref: .*`)
+	_, err = s.backend.GetCommit(ctx, "app", "--all")
+	c.Assert(err, check.ErrorMatches, `invalid ref: .*`)
+	_, err = s.backend.Compare(ctx, "app", "master", "--all")
+	c.Assert(err, check.ErrorMatches, `invalid ref: .*`)
+	_, err = s.backend.GetTree(ctx, "app", "--full-tree", "")
+	c.Assert(err, check.ErrorMatches, `invalid ref: .*`)
+	_, err = s.backend.GetArchive(ctx, "app", "--output="+output, gandalf.Tar)
+	c.Assert(err, check.ErrorMatches, `invalid ref: .*`)
+	_, err = os.Stat(output)
+	c.Assert(os.IsNotExist(err), check.Equals, true)
+}
+
+func (s *S) TestGetHealthCheck(c *check.C) {
+	result, err := s.backend.GetHealthCheck(ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(result), check.Equals, "WORKING")
+}
+
+func (s *S) TestGetBranches(c *check.C) {
+	s.pushCommits(c, "app")
+	branches, err := s.backend.GetBranches(ctx, "app")
+	c.Assert(err, check.IsNil)
+	c.Assert(branches, check.HasLen, 2)
+	c.Assert(branches[0].Name, check.Equals, "feature")
+	c.Assert(branches[0].Subject, check.Equals, "Add feature")
+	c.Assert(branches[0].Author.Email, check.Equals, "joaojose@eu.com")
+	c.Assert(branches[1].Name, check.Equals, "master")
+	log, err := s.backend.GetLog(ctx, "app", "master", "", 1)
+	c.Assert(err, check.IsNil)
+	c.Assert(branches[1].Ref, check.Equals, log.Commits[0].Ref)
+	c.Assert(branches[1].CreatedAt.String(), check.Equals, "Tue Dec  1 18:57:08 2015 -0200")
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalflocal
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/tsuru/go-gandalfclient/internal/atomicfile"
+)
+
+const storeFile = "gandalf.json"
+
+type repositoryMeta struct {
+	Users    []string `json:"users"`
+	IsPublic bool     `json:"ispublic"`
+}
+
+// store is the local replacement for the Gandalf database, holding users,
+// their keys and the access to each repository.
+type store struct {
+	Users        map[string]map[string]string `json:"users"`
+	Repositories map[string]*repositoryMeta   `json:"repositories"`
+}
+
+func (b *Backend) load() (*store, error) {
+	s := store{
+		Users:        map[string]map[string]string{},
+		Repositories: map[string]*repositoryMeta{},
+	}
+	data, err := ioutil.ReadFile(filepath.Join(b.root, storeFile))
+	if os.IsNotExist(err) {
+		return &s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (b *Backend) save(s *store) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = atomicfile.WriteFile(filepath.Join(b.root, storeFile), bytes.NewReader(data))
+	return err
+}
+
+// update loads the store, applies fn and saves the store if fn succeeds.
+func (b *Backend) update(fn func(s *store) error) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s, err := b.load()
+	if err != nil {
+		return err
+	}
+	if err := fn(s); err != nil {
+		return err
+	}
+	return b.save(s)
+}
+
+// view loads the store and calls fn with it.
+func (b *Backend) view(fn func(s *store) error) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s, err := b.load()
+	if err != nil {
+		return err
+	}
+	return fn(s)
+}
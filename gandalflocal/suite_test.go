@@ -0,0 +1,58 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalflocal
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct {
+	backend *Backend
+}
+
+var _ = check.Suite(&S{})
+
+var ctx = context.Background()
+
+func (s *S) SetUpSuite(c *check.C) {
+	if _, err := exec.LookPath("git"); err != nil {
+		c.Skip("git is not installed")
+	}
+}
+
+func (s *S) SetUpTest(c *check.C) {
+	var err error
+	s.backend, err = New(c.MkDir())
+	c.Assert(err, check.IsNil)
+}
+
+// gitEnv is the environment used to create commits in tests, so they do
+// not depend on the git configuration of the machine.
+var gitEnv = append(os.Environ(),
+	"GIT_AUTHOR_NAME=Joao Jose",
+	"GIT_AUTHOR_EMAIL=joaojose@eu.com",
+	"GIT_AUTHOR_DATE=1449003428 -0200",
+	"GIT_COMMITTER_NAME=Joao Jose",
+	"GIT_COMMITTER_EMAIL=joaojose@eu.com",
+	"GIT_COMMITTER_DATE=1449003428 -0200",
+	"GIT_CONFIG_NOSYSTEM=1",
+	"HOME=/nonexistent",
+)
+
+func run(c *check.C, dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = gitEnv
+	out, err := cmd.CombinedOutput()
+	c.Assert(err, check.IsNil, check.Commentf("git %v: %s", args, out))
+	return string(out)
+}
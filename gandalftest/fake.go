@@ -0,0 +1,243 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package gandalftest provides utilities for testing code that talks to
+// Gandalf through the go-gandalfclient package.
+package gandalftest
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	gandalf "github.com/tsuru/go-gandalfclient"
+)
+
+// Call is a method call recorded by Fake.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Fake is an implementation of gandalf.API that records every call and
+// returns the responses configured in its function fields. When a function
+// is nil, the method succeeds: mutating methods return the entity they
+// would have created and read methods return zero values. Call options are
+// not recorded nor passed to the function fields.
+//
+// Fake is safe for concurrent use, as long as the function fields are not
+// changed while it is in use.
+type Fake struct {
+	NewRepositoryFunc    func(ctx context.Context, name string, users []string, isPublic bool) (gandalf.Repository, error)
+	GetRepositoryFunc    func(ctx context.Context, name string) (gandalf.Repository, error)
+	RemoveRepositoryFunc func(ctx context.Context, name string) error
+	NewUserFunc          func(ctx context.Context, name string, keys map[string]string) (gandalf.User, error)
+	RemoveUserFunc       func(ctx context.Context, name string) error
+	GrantAccessFunc      func(ctx context.Context, rNames, uNames []string) error
+	RevokeAccessFunc     func(ctx context.Context, rNames, uNames []string) error
+	AddKeyFunc           func(ctx context.Context, uName string, key map[string]string) error
+	UpdateKeyFunc        func(ctx context.Context, uName, kName, kBody string) error
+	RemoveKeyFunc        func(ctx context.Context, uName, kName string) error
+	ListKeysFunc         func(ctx context.Context, uName string) (map[string]string, error)
+	GetDiffFunc          func(ctx context.Context, repo, previousCommit, lastCommit string) (string, error)
+	GetLogFunc           func(ctx context.Context, repo, ref, path string, total int) (gandalf.Log, error)
+	GetCommitFunc        func(ctx context.Context, repo, ref string) (gandalf.CommitDetail, error)
+	CompareFunc          func(ctx context.Context, repo, base, head string) (gandalf.Comparison, error)
+	GetBranchesFunc      func(ctx context.Context, repo string) ([]gandalf.Branch, error)
+	GetTreeFunc          func(ctx context.Context, repo, ref, path string) ([]gandalf.TreeEntry, error)
+	GetArchiveFunc       func(ctx context.Context, repo, ref string, format gandalf.ArchiveFormat) (io.ReadCloser, error)
+	GetHealthCheckFunc   func(ctx context.Context) ([]byte, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ gandalf.API = &Fake{}
+
+func (f *Fake) record(method string, args ...interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns the calls received by the fake, in order.
+func (f *Fake) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	calls := make([]Call, len(f.calls))
+	copy(calls, f.calls)
+	return calls
+}
+
+// Methods returns the names of the methods called on the fake, in order.
+func (f *Fake) Methods() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	methods := make([]string, len(f.calls))
+	for i, call := range f.calls {
+		methods[i] = call.Method
+	}
+	return methods
+}
+
+// Reset forgets all recorded calls.
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = nil
+}
+
+func (f *Fake) NewRepository(ctx context.Context, name string, users []string, isPublic bool, opts ...gandalf.CallOption) (gandalf.Repository, error) {
+	f.record("NewRepository", name, users, isPublic)
+	if f.NewRepositoryFunc != nil {
+		return f.NewRepositoryFunc(ctx, name, users, isPublic)
+	}
+	return gandalf.Repository{Name: name, Users: users, IsPublic: isPublic}, nil
+}
+
+func (f *Fake) GetRepository(ctx context.Context, name string, opts ...gandalf.CallOption) (gandalf.Repository, error) {
+	f.record("GetRepository", name)
+	if f.GetRepositoryFunc != nil {
+		return f.GetRepositoryFunc(ctx, name)
+	}
+	return gandalf.Repository{Name: name}, nil
+}
+
+func (f *Fake) RemoveRepository(ctx context.Context, name string, opts ...gandalf.CallOption) error {
+	f.record("RemoveRepository", name)
+	if f.RemoveRepositoryFunc != nil {
+		return f.RemoveRepositoryFunc(ctx, name)
+	}
+	return nil
+}
+
+func (f *Fake) NewUser(ctx context.Context, name string, keys map[string]string, opts ...gandalf.CallOption) (gandalf.User, error) {
+	f.record("NewUser", name, keys)
+	if f.NewUserFunc != nil {
+		return f.NewUserFunc(ctx, name, keys)
+	}
+	return gandalf.User{Name: name, Keys: keys}, nil
+}
+
+func (f *Fake) RemoveUser(ctx context.Context, name string, opts ...gandalf.CallOption) error {
+	f.record("RemoveUser", name)
+	if f.RemoveUserFunc != nil {
+		return f.RemoveUserFunc(ctx, name)
+	}
+	return nil
+}
+
+func (f *Fake) GrantAccess(ctx context.Context, rNames, uNames []string, opts ...gandalf.CallOption) error {
+	f.record("GrantAccess", rNames, uNames)
+	if f.GrantAccessFunc != nil {
+		return f.GrantAccessFunc(ctx, rNames, uNames)
+	}
+	return nil
+}
+
+func (f *Fake) RevokeAccess(ctx context.Context, rNames, uNames []string, opts ...gandalf.CallOption) error {
+	f.record("RevokeAccess", rNames, uNames)
+	if f.RevokeAccessFunc != nil {
+		return f.RevokeAccessFunc(ctx, rNames, uNames)
+	}
+	return nil
+}
+
+func (f *Fake) AddKey(ctx context.Context, uName string, key map[string]string, opts ...gandalf.CallOption) error {
+	f.record("AddKey", uName, key)
+	if f.AddKeyFunc != nil {
+		return f.AddKeyFunc(ctx, uName, key)
+	}
+	return nil
+}
+
+func (f *Fake) UpdateKey(ctx context.Context, uName, kName, kBody string, opts ...gandalf.CallOption) error {
+	f.record("UpdateKey", uName, kName, kBody)
+	if f.UpdateKeyFunc != nil {
+		return f.UpdateKeyFunc(ctx, uName, kName, kBody)
+	}
+	return nil
+}
+
+func (f *Fake) RemoveKey(ctx context.Context, uName, kName string, opts ...gandalf.CallOption) error {
+	f.record("RemoveKey", uName, kName)
+	if f.RemoveKeyFunc != nil {
+		return f.RemoveKeyFunc(ctx, uName, kName)
+	}
+	return nil
+}
+
+func (f *Fake) ListKeys(ctx context.Context, uName string, opts ...gandalf.CallOption) (map[string]string, error) {
+	f.record("ListKeys", uName)
+	if f.ListKeysFunc != nil {
+		return f.ListKeysFunc(ctx, uName)
+	}
+	return map[string]string{}, nil
+}
+
+func (f *Fake) GetDiff(ctx context.Context, repo, previousCommit, lastCommit string, opts ...gandalf.CallOption) (string, error) {
+	f.record("GetDiff", repo, previousCommit, lastCommit)
+	if f.GetDiffFunc != nil {
+		return f.GetDiffFunc(ctx, repo, previousCommit, lastCommit)
+	}
+	return "", nil
+}
+
+func (f *Fake) GetLog(ctx context.Context, repo, ref, path string, total int, opts ...gandalf.CallOption) (gandalf.Log, error) {
+	f.record("GetLog", repo, ref, path, total)
+	if f.GetLogFunc != nil {
+		return f.GetLogFunc(ctx, repo, ref, path, total)
+	}
+	return gandalf.Log{}, nil
+}
+
+func (f *Fake) GetCommit(ctx context.Context, repo, ref string, opts ...gandalf.CallOption) (gandalf.CommitDetail, error) {
+	f.record("GetCommit", repo, ref)
+	if f.GetCommitFunc != nil {
+		return f.GetCommitFunc(ctx, repo, ref)
+	}
+	return gandalf.CommitDetail{Commit: gandalf.Commit{Ref: ref}}, nil
+}
+
+func (f *Fake) Compare(ctx context.Context, repo, base, head string, opts ...gandalf.CallOption) (gandalf.Comparison, error) {
+	f.record("Compare", repo, base, head)
+	if f.CompareFunc != nil {
+		return f.CompareFunc(ctx, repo, base, head)
+	}
+	return gandalf.Comparison{Base: base, Head: head}, nil
+}
+
+func (f *Fake) GetBranches(ctx context.Context, repo string, opts ...gandalf.CallOption) ([]gandalf.Branch, error) {
+	f.record("GetBranches", repo)
+	if f.GetBranchesFunc != nil {
+		return f.GetBranchesFunc(ctx, repo)
+	}
+	return nil, nil
+}
+
+func (f *Fake) GetTree(ctx context.Context, repo, ref, path string, opts ...gandalf.CallOption) ([]gandalf.TreeEntry, error) {
+	f.record("GetTree", repo, ref, path)
+	if f.GetTreeFunc != nil {
+		return f.GetTreeFunc(ctx, repo, ref, path)
+	}
+	return nil, nil
+}
+
+func (f *Fake) GetArchive(ctx context.Context, repo, ref string, format gandalf.ArchiveFormat, opts ...gandalf.CallOption) (io.ReadCloser, error) {
+	f.record("GetArchive", repo, ref, format)
+	if f.GetArchiveFunc != nil {
+		return f.GetArchiveFunc(ctx, repo, ref, format)
+	}
+	return ioutil.NopCloser(strings.NewReader("")), nil
+}
+
+func (f *Fake) GetHealthCheck(ctx context.Context, opts ...gandalf.CallOption) ([]byte, error) {
+	f.record("GetHealthCheck")
+	if f.GetHealthCheckFunc != nil {
+		return f.GetHealthCheckFunc(ctx)
+	}
+	return []byte("WORKING"), nil
+}
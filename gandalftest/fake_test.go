@@ -0,0 +1,59 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalftest
+
+import (
+	"context"
+	"errors"
+
+	gandalf "github.com/tsuru/go-gandalfclient"
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestFakeDefaults(c *check.C) {
+	var api gandalf.API = &Fake{}
+	r, err := api.NewRepository(ctx, "proj1", []string{"user1"}, true)
+	c.Assert(err, check.IsNil)
+	c.Assert(r, check.DeepEquals, gandalf.Repository{Name: "proj1", Users: []string{"user1"}, IsPublic: true})
+	u, err := api.NewUser(ctx, "user1", map[string]string{"k": "ssh-rsa AAAA"})
+	c.Assert(err, check.IsNil)
+	c.Assert(u, check.DeepEquals, gandalf.User{Name: "user1", Keys: map[string]string{"k": "ssh-rsa AAAA"}})
+	keys, err := api.ListKeys(ctx, "user1")
+	c.Assert(err, check.IsNil)
+	c.Assert(keys, check.HasLen, 0)
+	result, err := api.GetHealthCheck(ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(result), check.Equals, "WORKING")
+}
+
+func (s *S) TestFakeStubs(c *check.C) {
+	fake := Fake{
+		GetRepositoryFunc: func(ctx context.Context, name string) (gandalf.Repository, error) {
+			return gandalf.Repository{Name: name, GitURL: "git://gandalf/" + name + ".git"}, nil
+		},
+		GrantAccessFunc: func(ctx context.Context, rNames, uNames []string) error {
+			return errors.New("access denied")
+		},
+	}
+	r, err := fake.GetRepository(ctx, "proj1")
+	c.Assert(err, check.IsNil)
+	c.Assert(r.GitURL, check.Equals, "git://gandalf/proj1.git")
+	err = fake.GrantAccess(ctx, []string{"proj1"}, []string{"user1"})
+	c.Assert(err, check.ErrorMatches, "access denied")
+}
+
+func (s *S) TestFakeRecordsCalls(c *check.C) {
+	var fake Fake
+	fake.NewUser(ctx, "user1", nil)
+	fake.AddKey(ctx, "user1", map[string]string{"k": "ssh-rsa AAAA"})
+	fake.GrantAccess(ctx, []string{"proj1"}, []string{"user1"})
+	c.Assert(fake.Methods(), check.DeepEquals, []string{"NewUser", "AddKey", "GrantAccess"})
+	c.Assert(fake.Calls()[2], check.DeepEquals, Call{
+		Method: "GrantAccess",
+		Args:   []interface{}{[]string{"proj1"}, []string{"user1"}},
+	})
+	fake.Reset()
+	c.Assert(fake.Calls(), check.HasLen, 0)
+}
@@ -0,0 +1,245 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalftest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Mode defines whether a Recorder talks to the server or replays a
+// cassette.
+type Mode int
+
+const (
+	// ModeReplay serves responses from the cassette, failing requests that
+	// do not match any recorded interaction.
+	ModeReplay Mode = iota
+
+	// ModeRecord sends requests to the server and appends every
+	// interaction to the cassette.
+	ModeRecord
+)
+
+// Redacted replaces SSH key material in recorded bodies.
+const Redacted = "REDACTED"
+
+var sshKeyRegexp = regexp.MustCompile(`((?:ssh-(?:rsa|dss|ed25519)|ecdsa-sha2-nistp[0-9]+|sk-[a-z0-9-]+@openssh\.com)\s+)[A-Za-z0-9+/]+={0,3}`)
+
+// RedactKeys replaces the base64 body of the SSH public keys found in s,
+// keeping the key type and comment.
+func RedactKeys(s string) string {
+	return sshKeyRegexp.ReplaceAllString(s, "${1}"+Redacted)
+}
+
+// RecordedRequest is the part of a request used for matching.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Body   string `json:"body,omitempty"`
+}
+
+// RecordedResponse is a response stored in a cassette.
+type RecordedResponse struct {
+	StatusCode int         `json:"status"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Interaction is a request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder is an http.RoundTripper that records interactions with a
+// Gandalf server into a cassette file and replays them later, so client
+// tests can run without a server:
+//
+//	recorder, err := gandalftest.NewRecorder("testdata/repository.json", gandalftest.ModeReplay)
+//	client := gandalf.Client{Endpoint: "http://gandalf", Client: recorder.Client()}
+//
+// Requests are matched on method, path (including the query string) and
+// body. SSH key material is redacted both in the cassette and before
+// matching.
+type Recorder struct {
+	// Transport is used to reach the server in ModeRecord. Defaults to
+	// http.DefaultTransport.
+	Transport http.RoundTripper
+
+	mode Mode
+	path string
+
+	mu       sync.Mutex
+	cassette cassette
+	used     []bool
+}
+
+// NewRecorder returns a recorder backed by the cassette in the given path.
+// In ModeReplay the cassette must exist; in ModeRecord it is created, or
+// truncated when it already exists.
+func NewRecorder(path string, mode Mode) (*Recorder, error) {
+	r := Recorder{mode: mode, path: path}
+	switch mode {
+	case ModeReplay:
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("invalid cassette %s: %s", path, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	case ModeRecord:
+		if err := r.save(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid recorder mode: %d", mode)
+	}
+	return &r, nil
+}
+
+// Client returns an HTTP client that uses the recorder as transport.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, body, err := recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.mode == ModeRecord {
+		// Responses are recorded decompressed, so cassettes stay
+		// readable and keys in them can be redacted.
+		req = req.Clone(req.Context())
+		req.Header.Del("Accept-Encoding")
+		if body != nil {
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+		return r.record(req, recorded)
+	}
+	return r.replay(req, recorded)
+}
+
+func (r *Recorder) record(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	header := resp.Header.Clone()
+	header.Del("Date")
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     header,
+			Body:       RedactKeys(string(body)),
+		},
+	})
+	if err := r.save(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || interaction.Request != recorded {
+			continue
+		}
+		r.used[i] = true
+		header := interaction.Response.Header.Clone()
+		if header == nil {
+			header = make(http.Header)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(strings.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("gandalftest: no interaction in cassette %s matches %s %s with body %q", r.path, recorded.Method, recorded.Path, recorded.Body)
+}
+
+// Unused returns the recorded interactions that were not replayed yet.
+func (r *Recorder) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var unused []Interaction
+	for i, used := range r.used {
+		if !used {
+			unused = append(unused, r.cassette.Interactions[i])
+		}
+	}
+	return unused
+}
+
+func (r *Recorder) save() error {
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, append(data, '\n'), 0644)
+}
+
+func recordRequest(req *http.Request) (RecordedRequest, []byte, error) {
+	recorded := RecordedRequest{Method: req.Method, Path: req.URL.RequestURI()}
+	if req.Body == nil {
+		return recorded, nil, nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return recorded, nil, err
+	}
+	text := body
+	if strings.EqualFold(req.Header.Get("Content-Encoding"), "gzip") {
+		// Compressed bodies are recorded decompressed, so keys in them
+		// are redacted and requests match regardless of compression.
+		gr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return recorded, nil, err
+		}
+		if text, err = ioutil.ReadAll(gr); err != nil {
+			return recorded, nil, err
+		}
+	}
+	recorded.Body = RedactKeys(string(text))
+	return recorded, body, nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalftest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+
+	gandalf "github.com/tsuru/go-gandalfclient"
+	"gopkg.in/check.v1"
+)
+
+const testKey = "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQC7+x/y= me@myhost"
+
+func gandalfServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repository/proj1":
+			w.Write([]byte(`{"name":"proj1","users":["user1"],"ispublic":false}`))
+		case "/user/user1/keys":
+			w.Write([]byte(`{"mykey":"` + testKey + `"}`))
+		case "/user/user1/key":
+			w.Write(nil)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+}
+
+func (s *S) TestRecorderRecordAndReplay(c *check.C) {
+	ts := gandalfServer()
+	cassettePath := filepath.Join(c.MkDir(), "cassette.json")
+	recorder, err := NewRecorder(cassettePath, ModeRecord)
+	c.Assert(err, check.IsNil)
+	client := gandalf.Client{Endpoint: ts.URL, Client: recorder.Client()}
+	r, err := client.GetRepository(ctx, "proj1")
+	c.Assert(err, check.IsNil)
+	c.Assert(r.Users, check.DeepEquals, []string{"user1"})
+	err = client.AddKey(ctx, "user1", map[string]string{"mykey": testKey})
+	c.Assert(err, check.IsNil)
+	keys, err := client.ListKeys(ctx, "user1")
+	c.Assert(err, check.IsNil)
+	c.Assert(keys["mykey"], check.Equals, testKey)
+	_, err = client.GetRepository(ctx, "proj2")
+	c.Assert(err, check.ErrorMatches, "not found\n")
+	ts.Close()
+
+	data, err := ioutil.ReadFile(cassettePath)
+	c.Assert(err, check.IsNil)
+	c.Assert(strings.Contains(string(data), "AAAAB3NzaC1yc2E"), check.Equals, false)
+	c.Assert(strings.Contains(string(data), "ssh-rsa REDACTED me@myhost"), check.Equals, true)
+
+	replayer, err := NewRecorder(cassettePath, ModeReplay)
+	c.Assert(err, check.IsNil)
+	client = gandalf.Client{Endpoint: ts.URL, Client: replayer.Client()}
+	r, err = client.GetRepository(ctx, "proj1")
+	c.Assert(err, check.IsNil)
+	c.Assert(r.Users, check.DeepEquals, []string{"user1"})
+	err = client.AddKey(ctx, "user1", map[string]string{"mykey": testKey})
+	c.Assert(err, check.IsNil)
+	keys, err = client.ListKeys(ctx, "user1")
+	c.Assert(err, check.IsNil)
+	c.Assert(keys["mykey"], check.Equals, "ssh-rsa REDACTED me@myhost")
+	_, err = client.GetRepository(ctx, "proj2")
+	c.Assert(err, check.ErrorMatches, "not found\n")
+	c.Assert(replayer.Unused(), check.HasLen, 0)
+}
+
+func (s *S) TestRecorderGzipRequests(c *check.C) {
+	ts := gandalfServer()
+	defer ts.Close()
+	cassettePath := filepath.Join(c.MkDir(), "cassette.json")
+	recorder, err := NewRecorder(cassettePath, ModeRecord)
+	c.Assert(err, check.IsNil)
+	client := gandalf.Client{Endpoint: ts.URL, Client: recorder.Client(), GzipRequestsOver: 1}
+	err = client.AddKey(ctx, "user1", map[string]string{"mykey": testKey})
+	c.Assert(err, check.IsNil)
+	data, err := ioutil.ReadFile(cassettePath)
+	c.Assert(err, check.IsNil)
+	c.Assert(strings.Contains(string(data), `"body": "{\"mykey\":\"ssh-rsa REDACTED me@myhost\"}"`), check.Equals, true, check.Commentf("%s", data))
+
+	replayer, err := NewRecorder(cassettePath, ModeReplay)
+	c.Assert(err, check.IsNil)
+	client = gandalf.Client{Endpoint: ts.URL, Client: replayer.Client()}
+	err = client.AddKey(ctx, "user1", map[string]string{"mykey": testKey})
+	c.Assert(err, check.IsNil)
+	c.Assert(replayer.Unused(), check.HasLen, 0)
+}
+
+func (s *S) TestRecorderReplayUnmatchedRequest(c *check.C) {
+	ts := gandalfServer()
+	defer ts.Close()
+	cassettePath := filepath.Join(c.MkDir(), "cassette.json")
+	recorder, err := NewRecorder(cassettePath, ModeRecord)
+	c.Assert(err, check.IsNil)
+	client := gandalf.Client{Endpoint: ts.URL, Client: recorder.Client()}
+	_, err = client.GetRepository(ctx, "proj1")
+	c.Assert(err, check.IsNil)
+
+	replayer, err := NewRecorder(cassettePath, ModeReplay)
+	c.Assert(err, check.IsNil)
+	client = gandalf.Client{Endpoint: ts.URL, Client: replayer.Client()}
+	err = client.AddKey(ctx, "user1", map[string]string{"mykey": testKey})
+	c.Assert(err, check.ErrorMatches, `(?s).*gandalftest: no interaction in cassette .* matches POST /user/user1/key with body .*REDACTED.*`)
+	c.Assert(replayer.Unused(), check.HasLen, 1)
+	_, err = client.GetRepository(ctx, "proj1")
+	c.Assert(err, check.IsNil)
+	_, err = client.GetRepository(ctx, "proj1")
+	c.Assert(err, check.ErrorMatches, ".*no interaction in cassette.*")
+}
+
+func (s *S) TestNewRecorderReplayMissingCassette(c *check.C) {
+	_, err := NewRecorder(filepath.Join(c.MkDir(), "missing.json"), ModeReplay)
+	c.Assert(err, check.NotNil)
+}
+
+func (s *S) TestRedactKeys(c *check.C) {
+	c.Assert(RedactKeys(`{"k":"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIG+/x== me@host"}`), check.Equals, `{"k":"ssh-ed25519 REDACTED me@host"}`)
+	c.Assert(RedactKeys("ecdsa-sha2-nistp256 AAAAE2VjZHNh"), check.Equals, "ecdsa-sha2-nistp256 REDACTED")
+	c.Assert(RedactKeys("no keys here"), check.Equals, "no keys here")
+}
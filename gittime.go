@@ -0,0 +1,128 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GitTimeFormat is the default date format used by git.
+var GitTimeFormat = "Mon Jan _2 15:04:05 2006 -0700"
+
+// gitTimeFormats are the layouts accepted when parsing a GitTime, besides
+// Unix timestamps.
+var gitTimeFormats = []string{
+	GitTimeFormat,
+	time.RFC3339Nano,
+	time.RFC1123Z,
+	"Mon, _2 Jan 2006 15:04:05 -0700",
+	"_2 Jan 2006 15:04:05 -0700",
+	time.RFC1123,
+}
+
+// epochRegexp matches Unix timestamps, optionally followed by a UTC offset
+// as in git's raw date format (e.g. "1449003428 -0200").
+var epochRegexp = regexp.MustCompile(`^(-?[0-9]+)(?: ([+-])([0-9]{2})([0-9]{2}))?$`)
+
+// GitTime is a time as reported by git. It keeps the UTC offset of the
+// original value, so it survives being marshaled and unmarshaled again.
+type GitTime time.Time
+
+// ParseGitTime parses s in git's default format, RFC 3339, RFC 2822 or as
+// a Unix timestamp, optionally followed by a UTC offset. An empty string
+// results in the zero time.
+func ParseGitTime(s string) (GitTime, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return GitTime{}, nil
+	}
+	if m := epochRegexp.FindStringSubmatch(s); m != nil {
+		sec, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return GitTime{}, fmt.Errorf("invalid git time %q", s)
+		}
+		t := time.Unix(sec, 0).UTC()
+		if m[2] != "" {
+			hours, _ := strconv.Atoi(m[3])
+			minutes, _ := strconv.Atoi(m[4])
+			offset := hours*3600 + minutes*60
+			if m[2] == "-" {
+				offset = -offset
+			}
+			t = t.In(time.FixedZone("", offset))
+		}
+		return GitTime(t), nil
+	}
+	for _, layout := range gitTimeFormats {
+		if t, err := time.Parse(layout, s); err == nil {
+			return GitTime(t), nil
+		}
+	}
+	return GitTime{}, fmt.Errorf("invalid git time %q", s)
+}
+
+// Time returns the time as a time.Time.
+func (c GitTime) Time() time.Time {
+	return time.Time(c)
+}
+
+// String returns the time in git's default format, or an empty string for
+// the zero time.
+func (c GitTime) String() string {
+	if c.Time().IsZero() {
+		return ""
+	}
+	return c.Time().Format(GitTimeFormat)
+}
+
+// MarshalText returns the time in the RFC 3339 format with nanoseconds, or
+// an empty text for the zero time.
+func (c GitTime) MarshalText() ([]byte, error) {
+	if c.Time().IsZero() {
+		return []byte{}, nil
+	}
+	return []byte(c.Time().Format(time.RFC3339Nano)), nil
+}
+
+// UnmarshalText accepts any of the formats supported by ParseGitTime.
+func (c *GitTime) UnmarshalText(text []byte) error {
+	t, err := ParseGitTime(string(text))
+	if err != nil {
+		return err
+	}
+	*c = t
+	return nil
+}
+
+// MarshalJSON returns the time as a JSON string in the RFC 3339 format,
+// or an empty JSON string for the zero time.
+func (c GitTime) MarshalJSON() ([]byte, error) {
+	text, err := c.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON accepts a JSON string in any of the formats supported by
+// ParseGitTime, a JSON number with a Unix timestamp or null.
+func (c *GitTime) UnmarshalJSON(raw []byte) error {
+	strRaw := string(raw)
+	if strRaw == "null" {
+		*c = GitTime{}
+		return nil
+	}
+	if strings.HasPrefix(strRaw, `"`) {
+		if err := json.Unmarshal(raw, &strRaw); err != nil {
+			return err
+		}
+	}
+	return c.UnmarshalText([]byte(strRaw))
+}
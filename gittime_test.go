@@ -0,0 +1,120 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"encoding/json"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestParseGitTime(c *check.C) {
+	expected := time.Date(2015, 12, 1, 18, 57, 8, 0, time.FixedZone("", -2*3600))
+	var tests = []string{
+		"Tue Dec 1 18:57:08 2015 -0200",
+		"2015-12-01T18:57:08-02:00",
+		"2015-12-01T18:57:08.000000-02:00",
+		"Tue, 01 Dec 2015 18:57:08 -0200",
+		"Tue, 1 Dec 2015 18:57:08 -0200",
+		"1 Dec 2015 18:57:08 -0200",
+		"1449003428 -0200",
+	}
+	for _, t := range tests {
+		parsed, err := ParseGitTime(t)
+		c.Assert(err, check.IsNil, check.Commentf("input %q", t))
+		c.Check(parsed.Time().Equal(expected), check.Equals, true, check.Commentf("input %q", t))
+		_, offset := parsed.Time().Zone()
+		c.Check(offset, check.Equals, -2*3600, check.Commentf("input %q", t))
+	}
+}
+
+func (s *S) TestParseGitTimeUnixEpoch(c *check.C) {
+	parsed, err := ParseGitTime("1449003428")
+	c.Assert(err, check.IsNil)
+	c.Assert(parsed.Time(), check.DeepEquals, time.Unix(1449003428, 0).UTC())
+}
+
+func (s *S) TestParseGitTimeEmptyAndInvalid(c *check.C) {
+	parsed, err := ParseGitTime("")
+	c.Assert(err, check.IsNil)
+	c.Assert(parsed.Time().IsZero(), check.Equals, true)
+	_, err = ParseGitTime("yesterday")
+	c.Assert(err, check.ErrorMatches, `invalid git time "yesterday"`)
+}
+
+func (s *S) TestGitTimeString(c *check.C) {
+	t, err := ParseGitTime("2015-12-01T18:57:08-02:00")
+	c.Assert(err, check.IsNil)
+	c.Assert(t.String(), check.Equals, "Tue Dec  1 18:57:08 2015 -0200")
+	c.Assert(GitTime{}.String(), check.Equals, "")
+}
+
+func (s *S) TestGitTimeJSONRoundTrip(c *check.C) {
+	for _, input := range []string{`"Tue Dec 1 18:57:08 2015 +0530"`, `"2015-12-01T18:57:08.123456789+05:30"`, `1449003428`} {
+		var t GitTime
+		err := json.Unmarshal([]byte(input), &t)
+		c.Assert(err, check.IsNil)
+		data, err := json.Marshal(t)
+		c.Assert(err, check.IsNil)
+		var again GitTime
+		err = json.Unmarshal(data, &again)
+		c.Assert(err, check.IsNil)
+		c.Assert(again.Time().Equal(t.Time()), check.Equals, true)
+		_, offset := t.Time().Zone()
+		_, againOffset := again.Time().Zone()
+		c.Assert(againOffset, check.Equals, offset)
+	}
+	data, err := json.Marshal(GitTime(time.Date(2015, 12, 1, 18, 57, 8, 0, time.FixedZone("", 19800))))
+	c.Assert(err, check.IsNil)
+	c.Assert(string(data), check.Equals, `"2015-12-01T18:57:08+05:30"`)
+}
+
+func (s *S) TestGitTimeUnmarshalEmptyResetsValue(c *check.C) {
+	t := GitTime(time.Now())
+	err := json.Unmarshal([]byte(`""`), &t)
+	c.Assert(err, check.IsNil)
+	c.Assert(t.Time().IsZero(), check.Equals, true)
+	t = GitTime(time.Now())
+	err = json.Unmarshal([]byte(`null`), &t)
+	c.Assert(err, check.IsNil)
+	c.Assert(t.Time().IsZero(), check.Equals, true)
+	data, err := json.Marshal(t)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(data), check.Equals, `""`)
+}
+
+func (s *S) TestGitTimeText(c *check.C) {
+	var t GitTime
+	err := t.UnmarshalText([]byte("Tue, 01 Dec 2015 18:57:08 -0200"))
+	c.Assert(err, check.IsNil)
+	text, err := t.MarshalText()
+	c.Assert(err, check.IsNil)
+	c.Assert(string(text), check.Equals, "2015-12-01T18:57:08-02:00")
+}
+
+func (s *S) TestCommitJSONRoundTrip(c *check.C) {
+	date, err := ParseGitTime("Tue Dec 1 18:57:08 2015 -0200")
+	c.Assert(err, check.IsNil)
+	commit := Commit{
+		Ref:       "30f221131c7d6ca50af7d46301a149c16e4f5561",
+		Author:    Author{Name: "Joao Jose", Email: "joaojose@eu.com", Date: date},
+		Subject:   "and when he falleth, he falleth ne'er to ascend again",
+		CreatedAt: date,
+		Parent:    []string{"75239a1976f92da9b39c24cdbfae4bfb473cd0e8"},
+	}
+	data, err := json.Marshal(commit)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(data), check.Equals, `{"ref":"30f221131c7d6ca50af7d46301a149c16e4f5561",`+
+		`"author":{"name":"Joao Jose","email":"joaojose@eu.com","date":"2015-12-01T18:57:08-02:00"},`+
+		`"committer":{"name":"","email":"","date":""},`+
+		`"subject":"and when he falleth, he falleth ne'er to ascend again",`+
+		`"createdAt":"2015-12-01T18:57:08-02:00","parent":["75239a1976f92da9b39c24cdbfae4bfb473cd0e8"]}`)
+	var decoded Commit
+	err = json.Unmarshal(data, &decoded)
+	c.Assert(err, check.IsNil)
+	c.Assert(decoded.CreatedAt.String(), check.Equals, commit.CreatedAt.String())
+	c.Assert(decoded.Author.Date.String(), check.Equals, commit.Author.Date.String())
+}
@@ -5,4 +5,5 @@ go 1.15
 require (
 	github.com/niemeyer/pretty v0.0.0-20200227124842-a10e7caefd8e // indirect
 	gopkg.in/check.v1 v1.0.0-20200902074654-038fdea0a05b
+	gopkg.in/yaml.v2 v2.4.0
 )
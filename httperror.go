@@ -0,0 +1,151 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxErrorBody is the number of bytes read from the body of failed
+	// responses.
+	maxErrorBody = 64 * 1024
+
+	// maxReasonLength caps the length of HTTPError.Reason.
+	maxReasonLength = 1024
+)
+
+// requestIDHeaders are the headers checked, in order, for the ID of a
+// request.
+var requestIDHeaders = []string{"X-Request-Id", "X-Correlation-Id", "X-Amzn-Trace-Id"}
+
+// HTTPError is returned when the server responds with an error, or, for
+// read operations, when it can't be reached.
+type HTTPError struct {
+	Code int
+
+	// Reason is the body of the response, truncated to 1024 bytes.
+	Reason string
+
+	// Method and Path identify the request, without its query string.
+	Method string
+	Path   string
+
+	Header    http.Header
+	RequestID string
+
+	// Message is the error message of JSON bodies, taken from their
+	// "message", "error" or "msg" field.
+	Message string
+
+	// Err is the error that prevented the request from being sent.
+	Err error
+}
+
+func (e *HTTPError) Error() string {
+	return e.Reason
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// Temporary reports whether the error is likely to go away, because the
+// server could not be reached, was unavailable or throttled the request.
+func (e *HTTPError) Temporary() bool {
+	var connErr *connectionError
+	if errors.As(e.Err, &connErr) {
+		return true
+	}
+	switch e.Code {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Retryable reports whether the request may be sent again: the error is
+// temporary and either the request is idempotent or the server is known
+// not to have handled it.
+func (e *HTTPError) Retryable() bool {
+	if !e.Temporary() {
+		return false
+	}
+	if isIdempotent(e.Method) {
+		return true
+	}
+	return e.Code == http.StatusTooManyRequests || e.Code == http.StatusServiceUnavailable
+}
+
+// newHTTPError builds the error of a failed response, reading at most
+// maxErrorBody bytes of its body.
+func newHTTPError(response *http.Response) *HTTPError {
+	body, _ := ioutil.ReadAll(io.LimitReader(response.Body, maxErrorBody))
+	e := HTTPError{
+		Code:    response.StatusCode,
+		Reason:  truncate(string(body), maxReasonLength),
+		Header:  response.Header,
+		Message: errorMessage(body),
+	}
+	if request := response.Request; request != nil {
+		e.Method = request.Method
+		e.Path = request.URL.Path
+	}
+	e.RequestID = requestID(response.Header)
+	return &e
+}
+
+// requestID returns the ID of the request in the response headers.
+func requestID(header http.Header) string {
+	for _, h := range requestIDHeaders {
+		if id := header.Get(h); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// errorMessage returns the message of a JSON error body.
+func errorMessage(body []byte) string {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return ""
+	}
+	for _, key := range []string{"message", "error", "msg"} {
+		if msg, ok := fields[key].(string); ok && msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
+
+// truncate cuts s to at most max bytes, without splitting UTF-8
+// characters, noting how much was left out.
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s... (%d bytes truncated)", s[:cut], len(s)-cut)
+}
+
+// pathOf returns path without its query string.
+func pathOf(path string) string {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		return path[:i]
+	}
+	return path
+}
@@ -0,0 +1,86 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestHTTPErrorDetails(c *check.C) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "req-123")
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"message":"repository already exists"}`))
+	}))
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	_, err := client.NewRepository(ctx, "app", []string{"alice"}, false)
+	var httpErr *HTTPError
+	c.Assert(errors.As(err, &httpErr), check.Equals, true)
+	c.Assert(httpErr.Code, check.Equals, http.StatusConflict)
+	c.Assert(httpErr.Method, check.Equals, "POST")
+	c.Assert(httpErr.Path, check.Equals, "/repository")
+	c.Assert(httpErr.RequestID, check.Equals, "req-123")
+	c.Assert(httpErr.Header.Get("Content-Type"), check.Equals, "application/json")
+	c.Assert(httpErr.Message, check.Equals, "repository already exists")
+	c.Assert(httpErr.Reason, check.Equals, `{"message":"repository already exists"}`)
+	c.Assert(httpErr.Temporary(), check.Equals, false)
+	c.Assert(httpErr.Retryable(), check.Equals, false)
+}
+
+func (s *S) TestHTTPErrorTruncatesReason(c *check.C) {
+	page := "<html>" + strings.Repeat("é", 1000) + "</html>"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, page, http.StatusBadGateway)
+	}))
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	_, err := client.GetRepository(ctx, "app")
+	var httpErr *HTTPError
+	c.Assert(errors.As(err, &httpErr), check.Equals, true)
+	c.Assert(httpErr.Path, check.Equals, "/repository/app")
+	c.Assert(httpErr.Message, check.Equals, "")
+	c.Assert(httpErr.Reason, check.Matches, `<html>é+\.\.\. \(\d+ bytes truncated\)`)
+	c.Assert(len(httpErr.Reason) < 1100, check.Equals, true)
+	c.Assert(httpErr.Temporary(), check.Equals, true)
+	c.Assert(httpErr.Retryable(), check.Equals, true)
+}
+
+func (s *S) TestHTTPErrorRetryable(c *check.C) {
+	var tests = []struct {
+		err       HTTPError
+		temporary bool
+		retryable bool
+	}{
+		{HTTPError{Code: 503, Method: "POST"}, true, true},
+		{HTTPError{Code: 429, Method: "POST"}, true, true},
+		{HTTPError{Code: 504, Method: "POST"}, true, false},
+		{HTTPError{Code: 504, Method: "DELETE"}, true, true},
+		{HTTPError{Code: 500, Method: "GET"}, false, false},
+		{HTTPError{Code: 404, Method: "GET"}, false, false},
+		{HTTPError{Code: 500, Method: "GET", Err: &connectionError{err: errors.New("refused")}}, true, true},
+	}
+	for _, t := range tests {
+		c.Check(t.err.Temporary(), check.Equals, t.temporary, check.Commentf("%d %s", t.err.Code, t.err.Method))
+		c.Check(t.err.Retryable(), check.Equals, t.retryable, check.Commentf("%d %s", t.err.Code, t.err.Method))
+	}
+}
+
+func (s *S) TestHTTPErrorOnConnectionFailure(c *check.C) {
+	client := Client{Endpoint: "http://127.0.0.1:1"}
+	_, err := client.GetRepository(ctx, "app")
+	var httpErr *HTTPError
+	c.Assert(errors.As(err, &httpErr), check.Equals, true)
+	c.Assert(httpErr.Code, check.Equals, 500)
+	c.Assert(httpErr.Method, check.Equals, "GET")
+	c.Assert(httpErr.Path, check.Equals, "/repository/app")
+	c.Assert(httpErr.Temporary(), check.Equals, true)
+}
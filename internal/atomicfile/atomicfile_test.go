@@ -0,0 +1,60 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package atomicfile
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type S struct{}
+
+var _ = check.Suite(&S{})
+
+func (s *S) TestWriteFile(c *check.C) {
+	dir := c.MkDir()
+	path := filepath.Join(dir, "data.json")
+	n, err := WriteFile(path, strings.NewReader("first"))
+	c.Assert(err, check.IsNil)
+	c.Assert(n, check.Equals, int64(5))
+	_, err = WriteFile(path, strings.NewReader("second"))
+	c.Assert(err, check.IsNil)
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(data), check.Equals, "second")
+	files, err := ioutil.ReadDir(dir)
+	c.Assert(err, check.IsNil)
+	c.Assert(files, check.HasLen, 1)
+}
+
+func (s *S) TestWriteFileKeepsOldContentOnError(c *check.C) {
+	dir := c.MkDir()
+	path := filepath.Join(dir, "data.json")
+	_, err := WriteFile(path, strings.NewReader("old"))
+	c.Assert(err, check.IsNil)
+	failing := io.MultiReader(strings.NewReader("new"), errorReader{})
+	_, err = WriteFile(path, failing)
+	c.Assert(err, check.ErrorMatches, "read failed")
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(data), check.Equals, "old")
+	files, err := ioutil.ReadDir(dir)
+	c.Assert(err, check.IsNil)
+	c.Assert(files, check.HasLen, 1)
+}
+
+type errorReader struct{}
+
+func (errorReader) Read(p []byte) (int, error) {
+	return 0, errors.New("read failed")
+}
@@ -0,0 +1,41 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+var errInvalidKey = errors.New("invalid SSH public key")
+
+// KeyFingerprint returns the SHA256 fingerprint of an SSH public key in
+// the authorized_keys format, in the same form printed by ssh-keygen -l
+// (e.g. SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8).
+func KeyFingerprint(key string) (string, error) {
+	fields := strings.Fields(key)
+	if len(fields) < 2 {
+		return "", errInvalidKey
+	}
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return "", errInvalidKey
+	}
+	sum := sha256.Sum256(blob)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:]), nil
+}
+
+// keyFingerprint is like KeyFingerprint, but falls back to hashing the
+// whole content when it is not a valid key, so it can be used wherever the
+// key material must not be exposed.
+func keyFingerprint(key string) string {
+	if fp, err := KeyFingerprint(key); err == nil {
+		return fp
+	}
+	sum := sha256.Sum256([]byte(key))
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,35 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"strings"
+
+	"gopkg.in/check.v1"
+)
+
+const (
+	testKey            = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAILDT9K8hm54Np5acikb+QFeFdh6zwKju5yvAdTBSUnnU me@myhost"
+	testKeyFingerprint = "SHA256:bkYD2T2UPft6GQgWWS4hf+awg5pU6bIv9O4/6DXAo28"
+)
+
+func (s *S) TestKeyFingerprint(c *check.C) {
+	fp, err := KeyFingerprint(testKey)
+	c.Assert(err, check.IsNil)
+	c.Assert(fp, check.Equals, testKeyFingerprint)
+	fp, err = KeyFingerprint(strings.TrimSuffix(testKey, " me@myhost"))
+	c.Assert(err, check.IsNil)
+	c.Assert(fp, check.Equals, testKeyFingerprint)
+}
+
+func (s *S) TestKeyFingerprintInvalidKey(c *check.C) {
+	_, err := KeyFingerprint("ssh-rsa")
+	c.Assert(err, check.Equals, errInvalidKey)
+	_, err = KeyFingerprint("ssh-rsa not-base64!")
+	c.Assert(err, check.Equals, errInvalidKey)
+	fp := keyFingerprint("ssh-rsa not-base64!")
+	c.Assert(fp, check.Matches, "SHA256:.+")
+	c.Assert(strings.Contains(fp, "base64"), check.Equals, false)
+}
@@ -0,0 +1,190 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var invalidKeyNameChars = regexp.MustCompile(`[^A-Za-z0-9._@-]+`)
+
+// ReadAuthorizedKeys reads the keys in an authorized_keys file, ignoring
+// blank lines, comments and the options before each key. Keys are named
+// after their comments, or "key-N" when they have none, and keys with the
+// same fingerprint are only returned once.
+func ReadAuthorizedKeys(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseAuthorizedKeys(f, path)
+}
+
+func parseAuthorizedKeys(r io.Reader, source string) (map[string]string, error) {
+	keys := newKeySet()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, comment, err := parseAuthorizedKey(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", source, n, err)
+		}
+		keys.add(comment, key)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return keys.keys, nil
+}
+
+// ReadKeyDir reads the *.pub files in dir, naming each key after its file
+// name without the extension. Keys with the same fingerprint are only
+// returned once.
+func ReadKeyDir(dir string) (map[string]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pub"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	keys := newKeySet()
+	for _, path := range paths {
+		fileKeys, err := ReadAuthorizedKeys(path)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSuffix(filepath.Base(path), ".pub")
+		names := make([]string, 0, len(fileKeys))
+		for n := range fileKeys {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			keys.add(name, fileKeys[n])
+		}
+	}
+	return keys.keys, nil
+}
+
+// parseAuthorizedKey returns the key of an authorized_keys line, without
+// its options, along with its comment. The key type is located by checking
+// it against the type encoded in the key itself, so options containing
+// spaces don't get in the way.
+func parseAuthorizedKey(line string) (string, string, error) {
+	fields := strings.Fields(line)
+	for i := 0; i+1 < len(fields); i++ {
+		blob, err := base64.StdEncoding.DecodeString(fields[i+1])
+		if err != nil || len(blob) < 4 {
+			continue
+		}
+		size := binary.BigEndian.Uint32(blob)
+		if uint64(len(blob)) < 4+uint64(size) || string(blob[4:4+size]) != fields[i] {
+			continue
+		}
+		return fields[i] + " " + fields[i+1], strings.Join(fields[i+2:], " "), nil
+	}
+	return "", "", errInvalidKey
+}
+
+// keySet collects keys, dropping duplicates by fingerprint and making
+// names unique.
+type keySet struct {
+	keys         map[string]string
+	fingerprints map[string]bool
+}
+
+func newKeySet() *keySet {
+	return &keySet{keys: map[string]string{}, fingerprints: map[string]bool{}}
+}
+
+func (s *keySet) add(name, key string) {
+	fp := keyFingerprint(key)
+	if s.fingerprints[fp] {
+		return
+	}
+	s.fingerprints[fp] = true
+	name = strings.Trim(invalidKeyNameChars.ReplaceAllString(name, "-"), "-")
+	if name == "" {
+		name = fmt.Sprintf("key-%d", len(s.keys)+1)
+	}
+	unique := name
+	for i := 2; s.keys[unique] != ""; i++ {
+		unique = fmt.Sprintf("%s-%d", name, i)
+	}
+	s.keys[unique] = key
+}
+
+// KeySyncReport lists the names of the keys handled by SyncKeys.
+type KeySyncReport struct {
+	Added     []string
+	Updated   []string
+	Unchanged []string
+}
+
+// SyncKeys makes sure the user has the given keys, adding the missing ones
+// and updating the ones whose name is already in use by a different key.
+// Keys the user already has, possibly under another name, are left
+// unchanged. Keys the user has but that are not in keys are kept.
+func SyncKeys(ctx context.Context, api API, user string, keys map[string]string) (KeySyncReport, error) {
+	var report KeySyncReport
+	current, err := api.ListKeys(ctx, user)
+	if err != nil {
+		return report, err
+	}
+	existing := map[string]bool{}
+	for _, key := range current {
+		existing[keyFingerprint(key)] = true
+	}
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	add := map[string]string{}
+	var update []string
+	for _, name := range names {
+		fp := keyFingerprint(keys[name])
+		switch {
+		case existing[fp]:
+			report.Unchanged = append(report.Unchanged, name)
+		case current[name] != "":
+			update = append(update, name)
+		default:
+			add[name] = keys[name]
+		}
+		existing[fp] = true
+	}
+	if len(add) > 0 {
+		if err := api.AddKey(ctx, user, add); err != nil {
+			return report, err
+		}
+		for _, name := range names {
+			if _, ok := add[name]; ok {
+				report.Added = append(report.Added, name)
+			}
+		}
+	}
+	for _, name := range update {
+		if err := api.UpdateKey(ctx, user, name, keys[name]); err != nil {
+			return report, err
+		}
+		report.Updated = append(report.Updated, name)
+	}
+	return report, nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/check.v1"
+)
+
+const (
+	testKey2 = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIIdrbayfY8YQF65D064nUTJFiVeQZ/jUz5xuOjZLpLIz user2@host"
+	testKey3 = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAII9IPbXLK0gN7gBVfmP99mPI0AivrPhD4+EyoK5AtZ8M user3@host"
+)
+
+func (s *S) TestReadAuthorizedKeys(c *check.C) {
+	path := filepath.Join(c.MkDir(), "authorized_keys")
+	content := "# team keys\n\n" +
+		testKey + "\n" +
+		`command="echo hi there",no-pty ` + testKey2 + "\n" +
+		strings.TrimSuffix(testKey3, " user3@host") + "\n" +
+		testKey + " duplicate\n"
+	err := ioutil.WriteFile(path, []byte(content), 0600)
+	c.Assert(err, check.IsNil)
+	keys, err := ReadAuthorizedKeys(path)
+	c.Assert(err, check.IsNil)
+	c.Assert(keys, check.DeepEquals, map[string]string{
+		"me@myhost":  strings.TrimSuffix(testKey, " me@myhost"),
+		"user2@host": strings.TrimSuffix(testKey2, " user2@host"),
+		"key-3":      strings.TrimSuffix(testKey3, " user3@host"),
+	})
+}
+
+func (s *S) TestReadAuthorizedKeysInvalidLine(c *check.C) {
+	path := filepath.Join(c.MkDir(), "authorized_keys")
+	err := ioutil.WriteFile(path, []byte(testKey+"\nssh-rsa garbage\n"), 0600)
+	c.Assert(err, check.IsNil)
+	_, err = ReadAuthorizedKeys(path)
+	c.Assert(err, check.ErrorMatches, ".*authorized_keys:2: invalid SSH public key")
+}
+
+func (s *S) TestReadKeyDir(c *check.C) {
+	dir := c.MkDir()
+	files := map[string]string{
+		"laptop.pub":  testKey,
+		"desktop.pub": testKey2,
+		"copy.pub":    testKey2,
+		"notes.txt":   testKey3,
+	}
+	for name, content := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content+"\n"), 0600)
+		c.Assert(err, check.IsNil)
+	}
+	keys, err := ReadKeyDir(dir)
+	c.Assert(err, check.IsNil)
+	c.Assert(keys, check.DeepEquals, map[string]string{
+		"laptop": strings.TrimSuffix(testKey, " me@myhost"),
+		"copy":   strings.TrimSuffix(testKey2, " user2@host"),
+	})
+}
+
+// keysHandler serves the keys of a single user.
+type keysHandler struct {
+	keys     map[string]string
+	requests []string
+}
+
+func (h *keysHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.requests = append(h.requests, r.Method+" "+r.URL.Path)
+	switch r.Method {
+	case http.MethodGet:
+		json.NewEncoder(w).Encode(h.keys)
+	case http.MethodPost:
+		json.NewDecoder(r.Body).Decode(&h.keys)
+	case http.MethodPut:
+		body, _ := ioutil.ReadAll(r.Body)
+		h.keys[filepath.Base(r.URL.Path)] = string(body)
+	}
+}
+
+func (s *S) TestSyncKeys(c *check.C) {
+	h := keysHandler{keys: map[string]string{"laptop": testKey, "desktop": testKey3}}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	report, err := SyncKeys(ctx, &client, "alice", map[string]string{
+		"old-laptop": testKey,
+		"desktop":    testKey2,
+		"phone":      testKey3,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(report, check.DeepEquals, KeySyncReport{
+		Updated:   []string{"desktop"},
+		Unchanged: []string{"old-laptop", "phone"},
+	})
+	c.Assert(h.requests, check.DeepEquals, []string{
+		"GET /user/alice/keys",
+		"PUT /user/alice/key/desktop",
+	})
+	c.Assert(h.keys["desktop"], check.Equals, testKey2)
+}
+
+func (s *S) TestSyncKeysAdd(c *check.C) {
+	h := keysHandler{keys: map[string]string{}}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	report, err := SyncKeys(ctx, &client, "alice", map[string]string{"laptop": testKey, "phone": testKey2})
+	c.Assert(err, check.IsNil)
+	c.Assert(report, check.DeepEquals, KeySyncReport{Added: []string{"laptop", "phone"}})
+	c.Assert(h.requests, check.DeepEquals, []string{"GET /user/alice/keys", "POST /user/alice/key"})
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxResponseSize is the limit of the response bodies read by the
+// client when MaxResponseSize is zero.
+const DefaultMaxResponseSize = 32 << 20
+
+// ErrResponseTooLarge is returned when the body of a response exceeds the
+// limit of its operation.
+type ErrResponseTooLarge struct {
+	Operation string
+	Limit     int64
+}
+
+func (e *ErrResponseTooLarge) Error() string {
+	return fmt.Sprintf("response to %s exceeds the limit of %d bytes", e.Operation, e.Limit)
+}
+
+// responseLimit returns the maximum body size of the operation, or zero
+// when it is unlimited. GetArchive is only limited when it is in
+// ResponseLimits, as archives are streamed to the caller.
+func (c *Client) responseLimit(op string) int64 {
+	limit, ok := c.ResponseLimits[op]
+	if !ok {
+		if op == "GetArchive" {
+			return 0
+		}
+		limit = c.MaxResponseSize
+		if limit == 0 {
+			limit = DefaultMaxResponseSize
+		}
+	}
+	if limit < 0 {
+		return 0
+	}
+	return limit
+}
+
+// limitedBody fails reads once more than limit bytes are read from the
+// body of a response.
+type limitedBody struct {
+	io.ReadCloser
+	op    string
+	limit int64
+	read  int64
+}
+
+// newLimitedBody limits body to limit bytes, unless limit is zero. Bodies
+// whose declared length is over the limit fail on the first read.
+func newLimitedBody(body io.ReadCloser, op string, limit, length int64) io.ReadCloser {
+	if limit <= 0 {
+		return body
+	}
+	b := limitedBody{ReadCloser: body, op: op, limit: limit}
+	if length > limit {
+		b.read = length
+	}
+	return &b
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.read > b.limit {
+		return 0, b.err()
+	}
+	if remaining := b.limit - b.read + 1; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if b.read > b.limit {
+		return n - int(b.read-b.limit), b.err()
+	}
+	return n, err
+}
+
+func (b *limitedBody) err() error {
+	return &ErrResponseTooLarge{Operation: b.op, Limit: b.limit}
+}
+
+// decodeJSON decodes the JSON value read from r into v. Errors reading r,
+// such as *ErrResponseTooLarge, are returned as is.
+func decodeJSON(r io.Reader, v interface{}) error {
+	err := json.NewDecoder(r).Decode(v)
+	if _, ok := err.(*ErrResponseTooLarge); ok {
+		return err
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		// Report truncated bodies as json.Unmarshal does.
+		return errors.New("Caught error decoding returned json: unexpected end of JSON input")
+	}
+	if err != nil {
+		return fmt.Errorf("Caught error decoding returned json: %s", err.Error())
+	}
+	return nil
+}
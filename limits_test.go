@@ -0,0 +1,85 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"gopkg.in/check.v1"
+)
+
+// bigHandler responds with prefix followed by size bytes, flushing them
+// in chunks so the response has no Content-Length.
+func bigHandler(prefix string, size int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, prefix)
+		chunk := strings.Repeat("x", 1024)
+		for written := 0; written < size; written += len(chunk) {
+			fmt.Fprint(w, chunk)
+			w.(http.Flusher).Flush()
+		}
+	})
+}
+
+func (s *S) TestResponseLimitPerOperation(c *check.C) {
+	ts := httptest.NewServer(bigHandler("", 64*1024))
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL, ResponseLimits: map[string]int64{"GetDiff": 10000}}
+	_, err := client.GetDiff(ctx, "repo", "1b970b076bbb30d708e262b402d4e31910e1dc10", "545b1904af34458704e2aa06ff1aaffad5289f8f")
+	var tooLarge *ErrResponseTooLarge
+	c.Assert(errors.As(err, &tooLarge), check.Equals, true)
+	c.Assert(tooLarge.Operation, check.Equals, "GetDiff")
+	c.Assert(tooLarge.Limit, check.Equals, int64(10000))
+	c.Assert(err, check.ErrorMatches, "response to GetDiff exceeds the limit of 10000 bytes")
+	client.ResponseLimits["GetDiff"] = -1
+	diff, err := client.GetDiff(ctx, "repo", "1b970b076bbb30d708e262b402d4e31910e1dc10", "545b1904af34458704e2aa06ff1aaffad5289f8f")
+	c.Assert(err, check.IsNil)
+	c.Assert(diff, check.HasLen, 64*1024)
+}
+
+func (s *S) TestResponseLimitDefault(c *check.C) {
+	ts := httptest.NewServer(bigHandler(`{"key":"`, 64*1024))
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL, MaxResponseSize: 4096}
+	_, err := client.ListKeys(ctx, "alice")
+	c.Assert(err, check.FitsTypeOf, &ErrResponseTooLarge{})
+	_, err = client.GetLog(ctx, "repo", "master", "", 1)
+	c.Assert(err, check.ErrorMatches, "response to GetLog exceeds the limit of 4096 bytes")
+}
+
+func (s *S) TestResponseLimitContentLength(c *check.C) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100000")
+		w.Write([]byte(`{"name":"repo"}`))
+	}))
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL, MaxResponseSize: 1000}
+	_, err := client.GetRepository(ctx, "repo")
+	c.Assert(err, check.FitsTypeOf, &ErrResponseTooLarge{})
+}
+
+func (s *S) TestResponseLimitArchive(c *check.C) {
+	ts := httptest.NewServer(bigHandler("", 64*1024))
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL, MaxResponseSize: 1000}
+	archive, err := client.GetArchive(ctx, "repo", "master", Zip)
+	c.Assert(err, check.IsNil)
+	data, err := ioutil.ReadAll(archive)
+	archive.Close()
+	c.Assert(err, check.IsNil)
+	c.Assert(data, check.HasLen, 64*1024)
+	client.ResponseLimits = map[string]int64{"GetArchive": 2048}
+	archive, err = client.GetArchive(ctx, "repo", "master", Zip)
+	c.Assert(err, check.IsNil)
+	defer archive.Close()
+	data, err = ioutil.ReadAll(archive)
+	c.Assert(err, check.FitsTypeOf, &ErrResponseTooLarge{})
+	c.Assert(data, check.HasLen, 2048)
+}
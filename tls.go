@@ -0,0 +1,156 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// TLSConfig describes how the client should establish TLS connections with
+// the Gandalf server. It is only used when Client.Client is nil.
+type TLSConfig struct {
+	// CAFile is the path to a PEM bundle with the root CAs trusted to
+	// sign the server certificate. The system pool is used when empty.
+	CAFile string
+
+	// CertFile and KeyFile are the paths to the PEM encoded client
+	// certificate and private key. They are reloaded whenever any of the
+	// files changes, so certificates can be rotated without restarting.
+	CertFile string
+	KeyFile  string
+
+	// MinVersion is the minimum TLS version accepted, defaulting to
+	// TLS 1.2.
+	MinVersion uint16
+
+	// ServerName overrides the name used to verify the server
+	// certificate.
+	ServerName string
+
+	InsecureSkipVerify bool
+}
+
+// Config builds a *tls.Config from the given settings.
+func (t *TLSConfig) Config() (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         t.MinVersion,
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+	if t.CAFile != "" {
+		pem, err := ioutil.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", t.CAFile)
+		}
+		config.RootCAs = pool
+	}
+	if t.CertFile != "" || t.KeyFile != "" {
+		if t.CertFile == "" || t.KeyFile == "" {
+			return nil, errors.New("both CertFile and KeyFile must be set")
+		}
+		loader := &keyPairLoader{certFile: t.CertFile, keyFile: t.KeyFile}
+		if _, err := loader.load(); err != nil {
+			return nil, err
+		}
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return loader.load()
+		}
+	}
+	return config, nil
+}
+
+// keyPairLoader loads a certificate and key pair from disk, reloading it
+// when the modification time of any of the files changes.
+type keyPairLoader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	certMod time.Time
+	keyMod  time.Time
+}
+
+func (l *keyPairLoader) load() (*tls.Certificate, error) {
+	certInfo, err := os.Stat(l.certFile)
+	if err != nil {
+		return l.fallback(err)
+	}
+	keyInfo, err := os.Stat(l.keyFile)
+	if err != nil {
+		return l.fallback(err)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.cert != nil && certInfo.ModTime().Equal(l.certMod) && keyInfo.ModTime().Equal(l.keyMod) {
+		return l.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(l.certFile, l.keyFile)
+	if err != nil {
+		// The files may be caught mid-rotation, with only one of them
+		// replaced. Keep using the previous pair until both match.
+		if l.cert != nil {
+			return l.cert, nil
+		}
+		return nil, err
+	}
+	l.cert = &cert
+	l.certMod = certInfo.ModTime()
+	l.keyMod = keyInfo.ModTime()
+	return l.cert, nil
+}
+
+func (l *keyPairLoader) fallback(err error) (*tls.Certificate, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.cert != nil {
+		return l.cert, nil
+	}
+	return nil, err
+}
+
+func (c *Client) httpClient() (*http.Client, error) {
+	if c.Client != nil {
+		return c.Client, nil
+	}
+	if c.TLS == nil {
+		return http.DefaultClient, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tlsClient == nil {
+		client, err := newTLSClient(c.TLS)
+		if err != nil {
+			return nil, err
+		}
+		c.tlsClient = client
+	}
+	return c.tlsClient, nil
+}
+
+func newTLSClient(t *TLSConfig) (*http.Client, error) {
+	config, err := t.Config()
+	if err != nil {
+		return nil, fmt.Errorf("invalid Gandalf TLS configuration: %s", err)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	return &http.Client{Transport: transport}, nil
+}
@@ -0,0 +1,194 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(c *check.C) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, check.IsNil)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "gandalf test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	c.Assert(err, check.IsNil)
+	cert, err := x509.ParseCertificate(der)
+	c.Assert(err, check.IsNil)
+	return &testCA{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+func (ca *testCA) issue(c *check.C, serial int64, name string, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, check.IsNil)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	c.Assert(err, check.IsNil)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	c.Assert(err, check.IsNil)
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM
+}
+
+type clientCertHandler struct {
+	names []string
+}
+
+func (h *clientCertHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for _, cert := range r.TLS.PeerCertificates {
+		h.names = append(h.names, cert.Subject.CommonName)
+	}
+	w.Write([]byte("WORKING"))
+}
+
+func newMutualTLSServer(c *check.C, ca *testCA, h http.Handler) *httptest.Server {
+	certPEM, keyPEM := ca.issue(c, 2, "gandalf", x509.ExtKeyUsageServerAuth)
+	serverCert, err := tls.X509KeyPair(certPEM, keyPEM)
+	c.Assert(err, check.IsNil)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	ts := httptest.NewUnstartedServer(h)
+	ts.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	}
+	ts.StartTLS()
+	return ts
+}
+
+func writeFile(c *check.C, path string, data []byte, mod time.Time) {
+	err := ioutil.WriteFile(path, data, 0600)
+	c.Assert(err, check.IsNil)
+	err = os.Chtimes(path, mod, mod)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *S) TestMutualTLS(c *check.C) {
+	ca := newTestCA(c)
+	h := clientCertHandler{}
+	ts := newMutualTLSServer(c, ca, &h)
+	defer ts.Close()
+	dir := c.MkDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	now := time.Now()
+	writeFile(c, caFile, ca.pem, now)
+	certPEM, keyPEM := ca.issue(c, 3, "tsuru", x509.ExtKeyUsageClientAuth)
+	writeFile(c, certFile, certPEM, now)
+	writeFile(c, keyFile, keyPEM, now)
+	client := Client{
+		Endpoint: ts.URL,
+		TLS:      &TLSConfig{CAFile: caFile, CertFile: certFile, KeyFile: keyFile},
+	}
+	result, err := client.GetHealthCheck(ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(result), check.Equals, "WORKING")
+	c.Assert(h.names, check.DeepEquals, []string{"tsuru"})
+}
+
+func (s *S) TestMutualTLSReloadsRotatedCertificate(c *check.C) {
+	ca := newTestCA(c)
+	h := clientCertHandler{}
+	ts := newMutualTLSServer(c, ca, &h)
+	defer ts.Close()
+	dir := c.MkDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	now := time.Now()
+	writeFile(c, caFile, ca.pem, now)
+	certPEM, keyPEM := ca.issue(c, 3, "old", x509.ExtKeyUsageClientAuth)
+	writeFile(c, certFile, certPEM, now)
+	writeFile(c, keyFile, keyPEM, now)
+	client := Client{
+		Endpoint: ts.URL,
+		TLS:      &TLSConfig{CAFile: caFile, CertFile: certFile, KeyFile: keyFile},
+	}
+	_, err := client.GetHealthCheck(ctx)
+	c.Assert(err, check.IsNil)
+	certPEM, keyPEM = ca.issue(c, 4, "new", x509.ExtKeyUsageClientAuth)
+	later := now.Add(time.Minute)
+	writeFile(c, certFile, certPEM, later)
+	writeFile(c, keyFile, keyPEM, later)
+	_, err = client.GetHealthCheck(ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(h.names, check.DeepEquals, []string{"old", "new"})
+}
+
+func (s *S) TestMutualTLSWithoutClientCertificate(c *check.C) {
+	ca := newTestCA(c)
+	ts := newMutualTLSServer(c, ca, &clientCertHandler{})
+	defer ts.Close()
+	caFile := filepath.Join(c.MkDir(), "ca.pem")
+	writeFile(c, caFile, ca.pem, time.Now())
+	client := Client{Endpoint: ts.URL, TLS: &TLSConfig{CAFile: caFile}}
+	_, err := client.GetHealthCheck(ctx)
+	c.Assert(err, check.NotNil)
+}
+
+func (s *S) TestTLSConfigMinVersion(c *check.C) {
+	config, err := (&TLSConfig{}).Config()
+	c.Assert(err, check.IsNil)
+	c.Assert(config.MinVersion, check.Equals, uint16(tls.VersionTLS12))
+	config, err = (&TLSConfig{MinVersion: tls.VersionTLS13}).Config()
+	c.Assert(err, check.IsNil)
+	c.Assert(config.MinVersion, check.Equals, uint16(tls.VersionTLS13))
+}
+
+func (s *S) TestTLSConfigInvalidFiles(c *check.C) {
+	_, err := (&TLSConfig{CertFile: "/tmp/cert.pem"}).Config()
+	c.Assert(err, check.ErrorMatches, "both CertFile and KeyFile must be set")
+	caFile := filepath.Join(c.MkDir(), "ca.pem")
+	writeFile(c, caFile, []byte("not a certificate"), time.Now())
+	_, err = (&TLSConfig{CAFile: caFile}).Config()
+	c.Assert(err, check.ErrorMatches, "no certificates found in .*")
+	client := Client{Endpoint: "https://127.0.0.1", TLS: &TLSConfig{CAFile: caFile}}
+	_, err = client.doRequest(ctx, "GET", "/", nil)
+	c.Assert(err, check.ErrorMatches, "invalid Gandalf TLS configuration: no certificates found in .*")
+}
@@ -0,0 +1,46 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// unixHTTPClient returns a copy of the configured HTTP client whose
+// transport dials the given socket, regardless of the request address.
+func (c *Client) unixHTTPClient(socket string) (*http.Client, error) {
+	base, err := c.httpClient()
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if client, ok := c.unixClients[socket]; ok {
+		return client, nil
+	}
+	var transport *http.Transport
+	switch t := base.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return nil, errors.New("unix socket endpoints require the HTTP client to use an *http.Transport")
+	}
+	transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, "unix", socket)
+	}
+	client := *base
+	client.Transport = transport
+	if c.unixClients == nil {
+		c.unixClients = make(map[string]*http.Client)
+	}
+	c.unixClients[socket] = &client
+	return &client, nil
+}
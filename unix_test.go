@@ -0,0 +1,56 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"net"
+	"net/http"
+	"path/filepath"
+
+	"gopkg.in/check.v1"
+)
+
+func serveUnix(c *check.C, h http.Handler) (string, func()) {
+	socket := filepath.Join(c.MkDir(), "gandalf.sock")
+	listener, err := net.Listen("unix", socket)
+	c.Assert(err, check.IsNil)
+	server := &http.Server{Handler: h}
+	go server.Serve(listener)
+	return socket, func() { server.Close() }
+}
+
+func (s *S) TestUnixSocketEndpoint(c *check.C) {
+	h := testHandler{content: `{"name":"repo-name"}`}
+	socket, stop := serveUnix(c, &h)
+	defer stop()
+	client := Client{Endpoint: "unix://" + socket}
+	r, err := client.GetRepository(ctx, "repo-name")
+	c.Assert(err, check.IsNil)
+	c.Assert(r.Name, check.Equals, "repo-name")
+	c.Assert(h.url, check.Equals, "/repository/repo-name?:name=repo-name")
+	c.Assert(h.method, check.Equals, "GET")
+	err = client.AddKey(ctx, "username", map[string]string{"pubkey": "ssh-rsa somekey me@myhost"})
+	c.Assert(err, check.IsNil)
+	c.Assert(h.url, check.Equals, "/user/username/key")
+	c.Assert(h.method, check.Equals, "POST")
+	c.Assert(string(h.body), check.Equals, `{"pubkey":"ssh-rsa somekey me@myhost"}`)
+}
+
+func (s *S) TestUnixSocketEndpointWithCustomClient(c *check.C) {
+	h := testHandler{content: "WORKING"}
+	socket, stop := serveUnix(c, &h)
+	defer stop()
+	client := Client{Endpoint: "unix://" + socket, Client: &http.Client{}}
+	result, err := client.GetHealthCheck(ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(result), check.Equals, "WORKING")
+	c.Assert(h.url, check.Equals, "/healthcheck")
+}
+
+func (s *S) TestUnixSocketEndpointConnectionError(c *check.C) {
+	client := Client{Endpoint: "unix:///nonexistent/gandalf.sock"}
+	_, err := client.doRequest(ctx, "GET", "/healthcheck", nil)
+	c.Assert(err, check.ErrorMatches, `Failed to connect to Gandalf server \(unix:///nonexistent/gandalf.sock\) - .*no such file or directory`)
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// nameRegexp holds the characters Gandalf accepts in repository,
+// namespace and user names.
+var nameRegexp = regexp.MustCompile(`^[\w\-+.@]+$`)
+
+// FieldError describes an invalid argument.
+type FieldError struct {
+	// Field is the name of the argument, with the index of the element
+	// for lists, as in "users[1]", or the key for maps, as in
+	// "keys[laptop]".
+	Field  string
+	Value  string
+	Reason string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s %q %s", e.Field, e.Value, e.Reason)
+}
+
+// ValidationError is returned, before sending any request, by the methods
+// of Client called with invalid names.
+type ValidationError struct {
+	Operation string
+	Fields    []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Error()
+	}
+	return fmt.Sprintf("invalid arguments to %s: %s", e.Operation, strings.Join(msgs, "; "))
+}
+
+// ValidateRepositoryName checks a repository name, optionally prefixed by
+// a namespace, as in "namespace/name".
+func ValidateRepositoryName(name string) error {
+	var v validator
+	v.repository("name", name)
+	return v.err("ValidateRepositoryName")
+}
+
+// ValidateUserName checks a user name.
+func ValidateUserName(name string) error {
+	var v validator
+	v.user("name", name)
+	return v.err("ValidateUserName")
+}
+
+// ValidateKeyName checks the name of a key.
+func ValidateKeyName(name string) error {
+	var v validator
+	v.key("name", name)
+	return v.err("ValidateKeyName")
+}
+
+// validator collects the errors of the arguments of an operation.
+type validator struct {
+	fields []FieldError
+}
+
+func (v *validator) fail(field, value, reason string) {
+	v.fields = append(v.fields, FieldError{Field: field, Value: value, Reason: reason})
+}
+
+func (v *validator) name(field, value, kind string) {
+	switch {
+	case value == "":
+		v.fail(field, value, "must not be empty")
+	case value == "." || value == "..":
+		v.fail(field, value, "is not a valid "+kind)
+	case !nameRegexp.MatchString(value):
+		v.fail(field, value, "must contain only letters, digits and the characters _-+.@")
+	}
+}
+
+func (v *validator) repository(field, name string) {
+	parts := strings.Split(name, "/")
+	if len(parts) > 2 {
+		v.fail(field, name, "must have at most one namespace")
+		return
+	}
+	if len(parts) == 2 {
+		v.name(field+" namespace", parts[0], "namespace")
+	}
+	v.name(field, parts[len(parts)-1], "repository name")
+}
+
+func (v *validator) repositories(field string, names []string) {
+	for i, name := range names {
+		v.repository(fmt.Sprintf("%s[%d]", field, i), name)
+	}
+}
+
+func (v *validator) user(field, name string) {
+	v.name(field, name, "user name")
+}
+
+func (v *validator) users(field string, names []string) {
+	for i, name := range names {
+		v.user(fmt.Sprintf("%s[%d]", field, i), name)
+	}
+}
+
+// key checks a key name, which is only used in paths, so only characters
+// with a meaning in URLs are rejected.
+func (v *validator) key(field, name string) {
+	switch {
+	case name == "":
+		v.fail(field, name, "must not be empty")
+	case name == "." || name == "..":
+		v.fail(field, name, "is not a valid key name")
+	case strings.ContainsAny(name, "/?#%\\") || strings.IndexFunc(name, isSpaceOrControl) >= 0:
+		v.fail(field, name, "must not contain whitespace or the characters /?#%\\")
+	}
+}
+
+func (v *validator) keys(field string, keys map[string]string) {
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		v.key(fmt.Sprintf("%s[%s]", field, name), name)
+	}
+}
+
+func isSpaceOrControl(r rune) bool {
+	return r <= ' ' || r == 0x7f
+}
+
+func (v *validator) err(op string) error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Operation: op, Fields: v.fields}
+}
@@ -0,0 +1,58 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"errors"
+	"net/http/httptest"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *S) TestValidateRepositoryName(c *check.C) {
+	for _, name := range []string{"app", "my-app_1.0", "team/app", "user@host+x"} {
+		c.Check(ValidateRepositoryName(name), check.IsNil, check.Commentf(name))
+	}
+	invalid := map[string]string{
+		"":          `invalid arguments to ValidateRepositoryName: name "" must not be empty`,
+		"..":        `invalid arguments to ValidateRepositoryName: name ".." is not a valid repository name`,
+		"a/b/c":     `invalid arguments to ValidateRepositoryName: name "a/b/c" must have at most one namespace`,
+		"/app":      `invalid arguments to ValidateRepositoryName: name namespace "" must not be empty`,
+		"app?x=1":   `invalid arguments to ValidateRepositoryName: name "app\?x=1" must contain only letters, digits and the characters _-\+\.@`,
+		"te am/app": `invalid arguments to ValidateRepositoryName: name namespace "te am" must contain .*`,
+	}
+	for name, msg := range invalid {
+		c.Check(ValidateRepositoryName(name), check.ErrorMatches, msg)
+	}
+}
+
+func (s *S) TestValidateUserAndKeyNames(c *check.C) {
+	c.Assert(ValidateUserName("alice@example.com"), check.IsNil)
+	c.Assert(ValidateUserName("alice/bob"), check.NotNil)
+	c.Assert(ValidateKeyName("laptop key@host"), check.ErrorMatches, `.*must not contain whitespace.*`)
+	c.Assert(ValidateKeyName("me@myhost"), check.IsNil)
+	c.Assert(ValidateKeyName("a/b"), check.NotNil)
+	c.Assert(ValidateKeyName("a%2fb"), check.NotNil)
+}
+
+func (s *S) TestClientValidatesBeforeSending(c *check.C) {
+	h := testHandler{}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	_, err := client.NewRepository(ctx, "a/b/c", []string{"alice", "bob?"}, false)
+	var verr *ValidationError
+	c.Assert(errors.As(err, &verr), check.Equals, true)
+	c.Assert(verr.Operation, check.Equals, "NewRepository")
+	c.Assert(verr.Fields, check.DeepEquals, []FieldError{
+		{Field: "name", Value: "a/b/c", Reason: "must have at most one namespace"},
+		{Field: "users[1]", Value: "bob?", Reason: "must contain only letters, digits and the characters _-+.@"},
+	})
+	err = client.AddKey(ctx, "alice", map[string]string{"bad/name": testKey})
+	c.Assert(err, check.ErrorMatches, `invalid arguments to AddKey: keys\[bad/name\] "bad/name" must not contain .*`)
+	_, err = client.GetLog(ctx, "app?ref=x", "master", "", 1)
+	c.Assert(err, check.FitsTypeOf, &ValidationError{})
+	c.Assert(h.url, check.Equals, "")
+}
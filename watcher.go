@@ -0,0 +1,266 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tsuru/go-gandalfclient/internal/atomicfile"
+)
+
+const (
+	defaultWatchInterval = time.Minute
+	watchPageSize        = 100
+)
+
+// WatchTarget is a ref of a repository watched by a Watcher.
+type WatchTarget struct {
+	Repository string `json:"repository"`
+	Ref        string `json:"ref"`
+}
+
+func (t WatchTarget) String() string {
+	return t.Repository + "@" + t.Ref
+}
+
+// NewCommits is the event sent by a Watcher when a ref moves.
+type NewCommits struct {
+	Target WatchTarget
+
+	// Commits are the commits added to the ref since the last poll,
+	// newest first.
+	Commits []Commit
+
+	// Rewritten is set when the previous head of the ref is no longer in
+	// its history, as after a force push, which is only known after the
+	// whole history is read. Commits then holds only the new head.
+	Rewritten bool
+}
+
+// CursorStore persists the last commit seen by a Watcher in each target.
+type CursorStore interface {
+	// Cursor returns the last commit seen in the target, or an empty
+	// string if the target was never polled.
+	Cursor(target WatchTarget) (string, error)
+	SetCursor(target WatchTarget, commit string) error
+}
+
+// MemoryCursorStore is a CursorStore that keeps cursors in memory. It is
+// safe for concurrent use.
+type MemoryCursorStore struct {
+	mu      sync.Mutex
+	cursors map[WatchTarget]string
+}
+
+func (s *MemoryCursorStore) Cursor(target WatchTarget) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cursors[target], nil
+}
+
+func (s *MemoryCursorStore) SetCursor(target WatchTarget, commit string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cursors == nil {
+		s.cursors = make(map[WatchTarget]string)
+	}
+	s.cursors[target] = commit
+	return nil
+}
+
+// FileCursorStore is a CursorStore that keeps cursors in a JSON file,
+// rewritten atomically on every change. It is safe for concurrent use
+// within a process.
+type FileCursorStore struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (s *FileCursorStore) load() (map[string]string, error) {
+	cursors := map[string]string{}
+	data, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return cursors, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &cursors); err != nil {
+		return nil, err
+	}
+	return cursors, nil
+}
+
+func (s *FileCursorStore) Cursor(target WatchTarget) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cursors, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	return cursors[target.String()], nil
+}
+
+func (s *FileCursorStore) SetCursor(target WatchTarget, commit string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cursors, err := s.load()
+	if err != nil {
+		return err
+	}
+	cursors[target.String()] = commit
+	data, err := json.MarshalIndent(cursors, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = atomicfile.WriteFile(s.Path, bytes.NewReader(data))
+	return err
+}
+
+// Watcher polls the log of refs and sends a NewCommits event whenever one
+// of them moves.
+//
+// The first time a target is polled, its head is stored as the cursor and
+// no event is sent, so the existing history is not replayed. The cursor is
+// only advanced after the event is delivered, so commits are not missed
+// when the process stops between polls.
+type Watcher struct {
+	API     API
+	Targets []WatchTarget
+
+	// Interval between polls of each target, defaulting to one minute.
+	Interval time.Duration
+
+	// MaxBackoff limits the time between polls of a target whose polls
+	// are failing, doubling the interval on every failure. It defaults
+	// to ten times the interval.
+	MaxBackoff time.Duration
+
+	// Store holds the cursors, defaulting to a MemoryCursorStore.
+	Store CursorStore
+
+	// OnError, when set, is called with the errors of each poll.
+	OnError func(target WatchTarget, err error)
+}
+
+// Run polls the targets until ctx is done, sending events to the given
+// channel. It returns the error of the context.
+func (w *Watcher) Run(ctx context.Context, events chan<- NewCommits) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	maxBackoff := w.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 10 * interval
+	}
+	store := w.Store
+	if store == nil {
+		store = &MemoryCursorStore{}
+	}
+	next := make([]time.Time, len(w.Targets))
+	delay := make([]time.Duration, len(w.Targets))
+	for {
+		now := time.Now()
+		wake := now.Add(interval)
+		for i, target := range w.Targets {
+			if next[i].After(now) {
+				if next[i].Before(wake) {
+					wake = next[i]
+				}
+				continue
+			}
+			if err := w.poll(ctx, store, target, events); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				if w.OnError != nil {
+					w.OnError(target, err)
+				}
+				if delay[i] == 0 {
+					delay[i] = interval
+				}
+				delay[i] *= 2
+				if delay[i] > maxBackoff {
+					delay[i] = maxBackoff
+				}
+			} else {
+				delay[i] = interval
+			}
+			next[i] = time.Now().Add(delay[i])
+			if next[i].Before(wake) {
+				wake = next[i]
+			}
+		}
+		timer := time.NewTimer(time.Until(wake))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (w *Watcher) poll(ctx context.Context, store CursorStore, target WatchTarget, events chan<- NewCommits) error {
+	cursor, err := store.Cursor(target)
+	if err != nil {
+		return err
+	}
+	if cursor == "" {
+		log, err := w.API.GetLog(ctx, target.Repository, target.Ref, "", 1)
+		if err != nil {
+			return err
+		}
+		if len(log.Commits) == 0 {
+			return nil
+		}
+		return store.SetCursor(target, log.Commits[0].Ref)
+	}
+	var (
+		commits []Commit
+		found   bool
+	)
+	// The log is read until the cursor is found, however many commits were
+	// added, so only the end of the history tells a rewrite apart.
+	for next := target.Ref; !found && next != ""; {
+		log, err := w.API.GetLog(ctx, target.Repository, next, "", watchPageSize)
+		if err != nil {
+			return err
+		}
+		for _, commit := range log.Commits {
+			if commit.Ref == cursor {
+				found = true
+				break
+			}
+			commits = append(commits, commit)
+		}
+		next = log.Next
+		if len(log.Commits) == 0 {
+			break
+		}
+	}
+	if len(commits) == 0 {
+		return nil
+	}
+	event := NewCommits{Target: target, Commits: commits}
+	if !found {
+		event.Commits = commits[:1]
+		event.Rewritten = true
+	}
+	select {
+	case events <- event:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return store.SetCursor(target, event.Commits[0].Ref)
+}
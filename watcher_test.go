@@ -0,0 +1,195 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+// logHandler serves the log of the master branch, newest first.
+type logHandler struct {
+	mu      sync.Mutex
+	commits []string
+	fail    int
+	polls   int
+}
+
+func (h *logHandler) push(refs ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, ref := range refs {
+		h.commits = append([]string{ref}, h.commits...)
+	}
+}
+
+func (h *logHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.polls++
+	if h.fail > 0 {
+		h.fail--
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	ref := r.URL.Query().Get("ref")
+	total, _ := strconv.Atoi(r.URL.Query().Get("total"))
+	start := 0
+	for i, commit := range h.commits {
+		if commit == ref {
+			start = i
+		}
+	}
+	end := start + total
+	if end > len(h.commits) {
+		end = len(h.commits)
+	}
+	var log Log
+	for _, commit := range h.commits[start:end] {
+		log.Commits = append(log.Commits, Commit{Ref: commit})
+	}
+	if end < len(h.commits) {
+		log.Next = h.commits[end]
+	}
+	json.NewEncoder(w).Encode(log)
+}
+
+func commitRefs(commits []Commit) []string {
+	var refs []string
+	for _, commit := range commits {
+		refs = append(refs, commit.Ref)
+	}
+	return refs
+}
+
+func (s *S) TestWatcher(c *check.C) {
+	h := logHandler{}
+	h.push("c1", "c2")
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	store := FileCursorStore{Path: filepath.Join(c.MkDir(), "cursors.json")}
+	target := WatchTarget{Repository: "app", Ref: "master"}
+	w := Watcher{
+		API:      &Client{Endpoint: ts.URL},
+		Targets:  []WatchTarget{target},
+		Interval: 10 * time.Millisecond,
+		Store:    &store,
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events := make(chan NewCommits)
+	done := make(chan error)
+	go func() { done <- w.Run(ctx, events) }()
+	for cursor := ""; cursor == ""; cursor, _ = store.Cursor(target) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	h.push("c3", "c4")
+	event := <-events
+	c.Assert(event.Target, check.Equals, target)
+	c.Assert(commitRefs(event.Commits), check.DeepEquals, []string{"c4", "c3"})
+	c.Assert(event.Rewritten, check.Equals, false)
+	cancel()
+	c.Assert(<-done, check.Equals, context.Canceled)
+	cursor, err := store.Cursor(target)
+	c.Assert(err, check.IsNil)
+	c.Assert(cursor, check.Equals, "c4")
+}
+
+func (s *S) TestWatcherResumesFromStore(c *check.C) {
+	h := logHandler{}
+	for i := 1; i <= 250; i++ {
+		h.push("c" + strconv.Itoa(i))
+	}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	target := WatchTarget{Repository: "app", Ref: "master"}
+	store := MemoryCursorStore{}
+	store.SetCursor(target, "c10")
+	w := Watcher{API: &Client{Endpoint: ts.URL}, Targets: []WatchTarget{target}, Store: &store}
+	events := make(chan NewCommits, 1)
+	err := w.poll(ctx, &store, target, events)
+	c.Assert(err, check.IsNil)
+	event := <-events
+	c.Assert(event.Commits, check.HasLen, 240)
+	c.Assert(event.Commits[0].Ref, check.Equals, "c250")
+	c.Assert(event.Commits[239].Ref, check.Equals, "c11")
+}
+
+func (s *S) TestWatcherReadsPastManyCommits(c *check.C) {
+	h := logHandler{}
+	for i := 1; i <= 1500; i++ {
+		h.push("c" + strconv.Itoa(i))
+	}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	target := WatchTarget{Repository: "app", Ref: "master"}
+	store := MemoryCursorStore{}
+	store.SetCursor(target, "c10")
+	w := Watcher{API: &Client{Endpoint: ts.URL}, Targets: []WatchTarget{target}}
+	events := make(chan NewCommits, 1)
+	err := w.poll(ctx, &store, target, events)
+	c.Assert(err, check.IsNil)
+	event := <-events
+	c.Assert(event.Rewritten, check.Equals, false)
+	c.Assert(event.Commits, check.HasLen, 1490)
+	c.Assert(event.Commits[0].Ref, check.Equals, "c1500")
+	c.Assert(event.Commits[1489].Ref, check.Equals, "c11")
+	cursor, err := store.Cursor(target)
+	c.Assert(err, check.IsNil)
+	c.Assert(cursor, check.Equals, "c1500")
+}
+
+func (s *S) TestWatcherRewrittenHistory(c *check.C) {
+	h := logHandler{}
+	h.push("c1", "c2")
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	target := WatchTarget{Repository: "app", Ref: "master"}
+	store := MemoryCursorStore{}
+	store.SetCursor(target, "gone")
+	w := Watcher{API: &Client{Endpoint: ts.URL}, Targets: []WatchTarget{target}}
+	events := make(chan NewCommits, 1)
+	err := w.poll(ctx, &store, target, events)
+	c.Assert(err, check.IsNil)
+	event := <-events
+	c.Assert(event.Rewritten, check.Equals, true)
+	c.Assert(commitRefs(event.Commits), check.DeepEquals, []string{"c2"})
+}
+
+func (s *S) TestWatcherBacksOff(c *check.C) {
+	h := logHandler{fail: 1000}
+	h.push("c1")
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	var mu sync.Mutex
+	var errs int
+	w := Watcher{
+		API:        &Client{Endpoint: ts.URL},
+		Targets:    []WatchTarget{{Repository: "app", Ref: "master"}},
+		Interval:   10 * time.Millisecond,
+		MaxBackoff: time.Second,
+		OnError: func(target WatchTarget, err error) {
+			mu.Lock()
+			errs++
+			mu.Unlock()
+		},
+	}
+	ctx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	w.Run(ctx, make(chan NewCommits))
+	mu.Lock()
+	defer mu.Unlock()
+	// Without backoff there would be about 20 polls; with it, they
+	// happen at 0, 20, 60 and 140ms.
+	c.Assert(errs >= 3 && errs <= 5, check.Equals, true, check.Commentf("%d errors", errs))
+}
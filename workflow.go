@@ -0,0 +1,179 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Step is an operation of a Workflow, along with the operation that undoes
+// it. Undo may be nil for operations that need no compensation.
+type Step struct {
+	Name string
+	Do   func(ctx context.Context) error
+	Undo func(ctx context.Context) error
+}
+
+// Workflow runs a sequence of operations as a unit: when a step fails, the
+// steps that already succeeded are undone in reverse order. For example,
+// provisioning an app:
+//
+//	err := gandalf.NewWorkflow(client).
+//		NewUser("app", nil).
+//		AddKey("app", map[string]string{"deploy": key}).
+//		NewRepository("app", []string{"app"}, false).
+//		Run(ctx)
+type Workflow struct {
+	api   API
+	steps []Step
+}
+
+// NewWorkflow returns an empty workflow that runs its operations on api.
+func NewWorkflow(api API) *Workflow {
+	return &Workflow{api: api}
+}
+
+// Add appends a custom step to the workflow.
+func (w *Workflow) Add(step Step) *Workflow {
+	w.steps = append(w.steps, step)
+	return w
+}
+
+// NewUser appends a step that creates the user, undone by RemoveUser.
+func (w *Workflow) NewUser(name string, keys map[string]string) *Workflow {
+	return w.Add(Step{
+		Name: "NewUser " + name,
+		Do: func(ctx context.Context) error {
+			_, err := w.api.NewUser(ctx, name, keys)
+			return err
+		},
+		Undo: func(ctx context.Context) error {
+			return w.api.RemoveUser(ctx, name)
+		},
+	})
+}
+
+// AddKey appends a step that adds keys to the user, undone by removing
+// each of them with RemoveKey.
+func (w *Workflow) AddKey(uName string, keys map[string]string) *Workflow {
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return w.Add(Step{
+		Name: "AddKey " + uName,
+		Do: func(ctx context.Context) error {
+			return w.api.AddKey(ctx, uName, keys)
+		},
+		Undo: func(ctx context.Context) error {
+			var errs []string
+			for _, name := range names {
+				if err := w.api.RemoveKey(ctx, uName, name); err != nil {
+					errs = append(errs, fmt.Sprintf("%s: %s", name, err))
+				}
+			}
+			if len(errs) > 0 {
+				return fmt.Errorf("failed to remove keys: %s", strings.Join(errs, "; "))
+			}
+			return nil
+		},
+	})
+}
+
+// NewRepository appends a step that creates the repository, undone by
+// RemoveRepository.
+func (w *Workflow) NewRepository(name string, users []string, isPublic bool) *Workflow {
+	return w.Add(Step{
+		Name: "NewRepository " + name,
+		Do: func(ctx context.Context) error {
+			_, err := w.api.NewRepository(ctx, name, users, isPublic)
+			return err
+		},
+		Undo: func(ctx context.Context) error {
+			return w.api.RemoveRepository(ctx, name)
+		},
+	})
+}
+
+// GrantAccess appends a step that grants access to the repositories,
+// undone by RevokeAccess.
+func (w *Workflow) GrantAccess(rNames, uNames []string) *Workflow {
+	return w.Add(Step{
+		Name: "GrantAccess " + strings.Join(rNames, ","),
+		Do: func(ctx context.Context) error {
+			return w.api.GrantAccess(ctx, rNames, uNames)
+		},
+		Undo: func(ctx context.Context) error {
+			return w.api.RevokeAccess(ctx, rNames, uNames)
+		},
+	})
+}
+
+// StepError is the failure of a step.
+type StepError struct {
+	Step string
+	Err  error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Step, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// WorkflowError is returned by Run when a step fails. It holds the
+// original failure and the failures of the steps that could not be
+// undone, which may have left resources behind.
+type WorkflowError struct {
+	StepError
+	RollbackErrors []StepError
+}
+
+func (e *WorkflowError) Error() string {
+	msg := "workflow failed at " + e.StepError.Error()
+	if len(e.RollbackErrors) == 0 {
+		return msg
+	}
+	rollback := make([]string, len(e.RollbackErrors))
+	for i := range e.RollbackErrors {
+		rollback[i] = e.RollbackErrors[i].Error()
+	}
+	return msg + "; rollback failed: " + strings.Join(rollback, "; ")
+}
+
+// Run executes the steps in order. If a step fails, the steps that
+// succeeded are undone in reverse order and a *WorkflowError is returned.
+// The rollback runs even if ctx is canceled, using a context without
+// deadline in that case.
+func (w *Workflow) Run(ctx context.Context) error {
+	for i, step := range w.steps {
+		err := step.Do(ctx)
+		if err == nil {
+			continue
+		}
+		wfErr := &WorkflowError{StepError: StepError{Step: step.Name, Err: err}}
+		rollbackCtx := ctx
+		if ctx.Err() != nil {
+			rollbackCtx = context.Background()
+		}
+		for j := i - 1; j >= 0; j-- {
+			done := w.steps[j]
+			if done.Undo == nil {
+				continue
+			}
+			if err := done.Undo(rollbackCtx); err != nil {
+				wfErr.RollbackErrors = append(wfErr.RollbackErrors, StepError{Step: done.Name, Err: err})
+			}
+		}
+		return wfErr
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2026 go-gandalfclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gandalf
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"gopkg.in/check.v1"
+)
+
+// sequenceHandler records the requests it receives and fails those whose
+// method and path are in fail.
+type sequenceHandler struct {
+	mu       sync.Mutex
+	fail     map[string]bool
+	requests []string
+}
+
+func (h *sequenceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	req := r.Method + " " + r.URL.Path
+	h.requests = append(h.requests, req)
+	if h.fail[req] {
+		http.Error(w, "failed: "+req, http.StatusInternalServerError)
+	}
+}
+
+func (s *S) TestWorkflow(c *check.C) {
+	h := sequenceHandler{}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	err := NewWorkflow(&client).
+		NewUser("app", nil).
+		AddKey("app", map[string]string{"deploy": testKey}).
+		NewRepository("app", []string{"app"}, false).
+		GrantAccess([]string{"app"}, []string{"admin"}).
+		Run(ctx)
+	c.Assert(err, check.IsNil)
+	c.Assert(h.requests, check.DeepEquals, []string{
+		"POST /user",
+		"POST /user/app/key",
+		"POST /repository",
+		"POST /repository/grant",
+	})
+}
+
+func (s *S) TestWorkflowRollback(c *check.C) {
+	h := sequenceHandler{fail: map[string]bool{"POST /repository": true}}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	err := NewWorkflow(&client).
+		NewUser("app", nil).
+		AddKey("app", map[string]string{"k2": testKey, "k1": testKey}).
+		NewRepository("app", []string{"app"}, false).
+		GrantAccess([]string{"app"}, []string{"admin"}).
+		Run(ctx)
+	c.Assert(err, check.ErrorMatches, "workflow failed at NewRepository app: failed: POST /repository\n")
+	wfErr, ok := err.(*WorkflowError)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(wfErr.Step, check.Equals, "NewRepository app")
+	c.Assert(wfErr.RollbackErrors, check.HasLen, 0)
+	var httpErr *HTTPError
+	c.Assert(errors.As(err, &httpErr), check.Equals, true)
+	c.Assert(httpErr.Code, check.Equals, http.StatusInternalServerError)
+	c.Assert(h.requests, check.DeepEquals, []string{
+		"POST /user",
+		"POST /user/app/key",
+		"POST /repository",
+		"DELETE /user/app/key/k1",
+		"DELETE /user/app/key/k2",
+		"DELETE /user/app",
+	})
+}
+
+func (s *S) TestWorkflowRollbackFailures(c *check.C) {
+	h := sequenceHandler{fail: map[string]bool{
+		"POST /repository/grant":   true,
+		"DELETE /repository/app":   true,
+		"DELETE /user/app/key/key": true,
+	}}
+	ts := httptest.NewServer(&h)
+	defer ts.Close()
+	client := Client{Endpoint: ts.URL}
+	var undone bool
+	err := NewWorkflow(&client).
+		Add(Step{
+			Name: "custom",
+			Do:   func(context.Context) error { return nil },
+			Undo: func(context.Context) error { undone = true; return nil },
+		}).
+		NewUser("app", nil).
+		AddKey("app", map[string]string{"key": testKey}).
+		NewRepository("app", []string{"app"}, false).
+		GrantAccess([]string{"app"}, []string{"admin"}).
+		Run(ctx)
+	wfErr, ok := err.(*WorkflowError)
+	c.Assert(ok, check.Equals, true)
+	c.Assert(wfErr.Step, check.Equals, "GrantAccess app")
+	c.Assert(wfErr.RollbackErrors, check.HasLen, 2)
+	c.Assert(wfErr.RollbackErrors[0].Step, check.Equals, "NewRepository app")
+	c.Assert(wfErr.RollbackErrors[1].Step, check.Equals, "AddKey app")
+	c.Assert(err, check.ErrorMatches, "(?s)workflow failed at GrantAccess app: .*; rollback failed: NewRepository app: .*; AddKey app: failed to remove keys: key: .*")
+	c.Assert(undone, check.Equals, true)
+	c.Assert(h.requests[len(h.requests)-1], check.Equals, "DELETE /user/app")
+}